@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/archive"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/internal/service"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+)
+
+type reconcileLikesResult struct {
+	ArticlesScanned         int32 `json:"articles_scanned"`
+	ArticlesCorrected       int32 `json:"articles_corrected"`
+	ReactionCountsCorrected int32 `json:"reaction_counts_corrected"`
+	RewritesCountsCorrected int32 `json:"rewrites_counts_corrected"`
+	DryRun                  bool  `json:"dry_run"`
+}
+
+func runReconcileLikes(args []string) error {
+	fs, out := newFlagSet("reconcile-likes", true)
+	batchSize := fs.Int("batch-size", 0, "articles per batch (default RECONCILE_LIKES_BATCH_SIZE)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return runOneShot(coreModule, func(ctx context.Context, cfg *config.Config, articles repos.ArticleRepo) error {
+		size := *batchSize
+		if size <= 0 {
+			size = cfg.Reconcile.BatchSize
+		}
+		resp, err := articles.ReconcileLikes(ctx, &article_protos.ReconcileLikesRequest{BatchSize: int32(size), DryRun: out.dryRun})
+		if err != nil {
+			return err
+		}
+		result := reconcileLikesResult{
+			ArticlesScanned:         resp.ArticlesScanned,
+			ArticlesCorrected:       resp.ArticlesCorrected,
+			ReactionCountsCorrected: resp.ReactionCountsCorrected,
+			RewritesCountsCorrected: resp.RewritesCountsCorrected,
+			DryRun:                  out.dryRun,
+		}
+		return out.print(os.Stdout, result, func(w io.Writer) {
+			fmt.Fprintf(w, "scanned %d articles, %s %d like counters, %d reaction counts and %d rewrite counters\n",
+				result.ArticlesScanned, verb(out.dryRun, "corrected", "correct"), result.ArticlesCorrected, result.ReactionCountsCorrected, result.RewritesCountsCorrected)
+		})
+	})
+}
+
+func runGCFiles(args []string) error {
+	fs, out := newFlagSet("gc-files", true)
+	minAge := fs.Duration("min-age", 24*time.Hour, "keep unreferenced objects younger than this, they may belong to an upload in progress")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return runOneShot(coreModule, func(ctx context.Context, gc *service.FilesGC) error {
+		report, err := gc.Run(ctx, *minAge, out.dryRun)
+		if err != nil {
+			return err
+		}
+		return out.print(os.Stdout, report, func(w io.Writer) {
+			fmt.Fprintf(w, "%s %d pictures of deleted articles and %d of %d objects (%d bytes)\n",
+				verb(out.dryRun, "deleted", "delete"), report.OrphanPictures, report.OrphanObjects, report.ObjectsScanned, report.BytesReclaimed)
+			if report.Failed > 0 {
+				fmt.Fprintf(w, "%d deletions failed, see the service log\n", report.Failed)
+			}
+		})
+	})
+}
+
+func runReindexSearch(args []string) error {
+	fs, out := newFlagSet("reindex-search", true)
+	batchSize := fs.Int("batch-size", 500, "articles per batch")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return runOneShot(coreModule, func(ctx context.Context, articles repos.ArticleRepo) error {
+		result, err := articles.ReindexArticles(ctx, *batchSize, out.dryRun)
+		if err != nil {
+			return err
+		}
+		return out.print(os.Stdout, result, func(w io.Writer) {
+			fmt.Fprintf(w, "scanned %d articles, %s derived content of %d and slugs of %d\n",
+				result.Scanned, verb(out.dryRun, "rebuilt", "rebuild"), result.ContentUpdated, result.SlugsAssigned)
+		})
+	})
+}
+
+func runSitemap(args []string) error {
+	fs, out := newFlagSet("sitemap", false)
+	force := fs.Bool("force", false, "compare every page even when no article changed, needed after SITE_BASE_URL changes")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return runOneShot(coreModule, func(ctx context.Context, sitemaps *service.SitemapGenerator) error {
+		report, err := sitemaps.Run(ctx, *force)
+		if err != nil {
+			return err
+		}
+		return out.print(os.Stdout, report, func(w io.Writer) {
+			if report.Skipped {
+				fmt.Fprintf(w, "%d articles in %d sitemap files, nothing changed\n", report.Articles, report.Pages)
+				return
+			}
+			fmt.Fprintf(w, "%d articles in %d sitemap files, wrote %d and deleted %d\n",
+				report.Articles, report.Pages, report.PagesWritten, report.PagesDeleted)
+		})
+	})
+}
+
+func runExport(args []string) error {
+	fs, out := newFlagSet("export", false)
+	output := fs.String("output", "-", `file to write the archive to, "-" writes to stdout`)
+	format := fs.String("format", archive.FormatJSONL, "archive format, jsonl or tar")
+	objects := fs.Bool("objects", false, "include the picture files themselves, needs --format tar")
+	compress := fs.Bool("gzip", false, "gzip the archive")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	opts := archive.ExportOptions{Format: *format, Objects: *objects, Compress: *compress}
+	if _, err := archive.NewWriter(io.Discard, opts.Format, opts.Objects, false); err != nil {
+		return err
+	}
+
+	export := func(ctx context.Context, store repos.ArchiveStore, files repos.MinIOStorage) error {
+		// the summary goes to stderr when the archive itself is written to stdout
+		w, report := io.Writer(os.Stdout), io.Writer(os.Stderr)
+		if *output != "-" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w, report = f, os.Stdout
+		}
+
+		summary, err := archive.Export(ctx, store, files, w, opts)
+		if err != nil {
+			if *output != "-" {
+				os.Remove(*output)
+			}
+			return err
+		}
+		return out.print(report, summary, func(w io.Writer) {
+			fmt.Fprintf(w, "exported %s\n", recordCounts(summary.Records))
+			if opts.Objects {
+				fmt.Fprintf(w, "exported %d objects (%d bytes), %d referenced objects were missing\n",
+					summary.Objects, summary.ObjectBytes, summary.MissingObjects)
+			}
+		})
+	}
+
+	// only connect to MinIO when the objects are wanted
+	if opts.Objects {
+		return runOneShot(coreModule, export)
+	}
+	return runOneShot(coreModule, func(ctx context.Context, store repos.ArchiveStore) error {
+		return export(ctx, store, nil)
+	})
+}
+
+func runImport(args []string) error {
+	fs, out := newFlagSet("import", true)
+	input := fs.String("input", "-", `archive to read, "-" reads from stdin`)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return runOneShot(coreModule, func(ctx context.Context, store repos.ArchiveStore, files repos.MinIOStorage) error {
+		r := io.Reader(os.Stdin)
+		if *input != "-" {
+			f, err := os.Open(*input)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		summary, err := archive.Import(ctx, store, files, r, out.dryRun)
+		if err != nil {
+			return err
+		}
+		return out.print(os.Stdout, summary, func(w io.Writer) {
+			fmt.Fprintf(w, "read %s from a version %d %s archive\n", recordCounts(summary.Records), summary.Version, summary.Format)
+			fmt.Fprintf(w, "%s %s, skipped %s already present\n",
+				verb(out.dryRun, "imported", "import"), recordCounts(summary.Imported), recordCounts(summary.Skipped))
+			if summary.Objects > 0 {
+				fmt.Fprintf(w, "%s %d objects\n", verb(out.dryRun, "restored", "restore"), summary.Objects)
+			}
+		})
+	})
+}
+
+// recordCounts renders per record type counts like "2 article, 0 slug"
+func recordCounts(counts map[string]int64) string {
+	parts := make([]string, 0, len(archive.RecordTypes))
+	for _, recordType := range archive.RecordTypes {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[recordType], recordType))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// verb picks the wording for what a command did or, in a dry run, would do
+func verb(dryRun bool, past, infinitive string) string {
+	if dryRun {
+		return "would " + infinitive
+	}
+	return past
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/dig"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+type (
+	// command is one subcommand of the binary
+	command struct {
+		name    string
+		summary string
+		run     func(args []string) error
+	}
+
+	// outputFlags are shared by every subcommand that changes or reports on data
+	outputFlags struct {
+		dryRun bool
+		json   bool
+	}
+)
+
+var commands = []command{
+	{"serve", "run the gRPC, REST and gRPC-Web servers (default)", runServe},
+	{"migrate", "apply, revert or list schema migrations", runMigrate},
+	{"reconcile-likes", "recompute like and reaction counters from article_reactions", runReconcileLikes},
+	{"gc-files", "delete pictures of deleted articles and unreferenced objects in MinIO", runGCFiles},
+	{"export", "write every article, slug, reaction and picture to a JSONL or tar archive", runExport},
+	{"import", "restore an archive written by export, pictures included", runImport},
+	{"reindex-search", "re-render article html, excerpts and reading metadata and assign missing slugs", runReindexSearch},
+	{"sitemap", "regenerate the sitemap files whose articles changed", runSitemap},
+}
+
+// run dispatches to the subcommand named by the first argument, no argument starts the servers
+func run(args []string) error {
+	if len(args) == 0 {
+		return runServe(nil)
+	}
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+	printUsage(os.Stderr)
+	return fmt.Errorf("unknown command %q", name)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: article_service <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "article_service <command> -h" for the flags of a command`)
+}
+
+// newFlagSet returns a flag set for the named command, withDryRun also registers --dry-run
+func newFlagSet(name string, withDryRun bool) (*flag.FlagSet, *outputFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	out := &outputFlags{}
+	fs.BoolVar(&out.json, "json", false, "print the result as JSON")
+	if withDryRun {
+		fs.BoolVar(&out.dryRun, "dry-run", false, "report what would change without writing anything")
+	}
+	return fs, out
+}
+
+// parseFlags parses args and rejects positional arguments the command does not take
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%s: unexpected argument %q", fs.Name(), positional[0])
+	}
+	return nil
+}
+
+// parseArgs parses flags wherever they appear in args and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// print writes v as indented JSON when --json is set, otherwise text renders it for humans
+func (o *outputFlags) print(w io.Writer, v any, text func(w io.Writer)) error {
+	if o.json {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text(w)
+	return nil
+}
+
+// runOneShot builds the given fx graph without any servers, calls fn with the dependencies it
+// asks for and closes the database again. fn may take a context.Context that is cancelled on
+// SIGINT or SIGTERM
+func runOneShot(opts fx.Option, fn any) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := fx.New(
+		opts,
+		fx.NopLogger,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Invoke(closeDBOnStop),
+		fx.Invoke(fn),
+	)
+	if err := app.Err(); err != nil {
+		// constructor failures come wrapped in the whole dependency path, operators want the cause
+		return dig.RootCause(err)
+	}
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	return app.Stop(context.Background())
+}
+
+func closeDBOnStop(lc fx.Lifecycle, db *gorm.DB) {
+	lc.Append(fx.StopHook(func() error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}))
+}
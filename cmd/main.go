@@ -2,20 +2,34 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/feeds"
+	"github.com/ruziba3vich/mm_article_service/internal/files"
+	"github.com/ruziba3vich/mm_article_service/internal/gateway"
+	"github.com/ruziba3vich/mm_article_service/internal/idgen"
+	"github.com/ruziba3vich/mm_article_service/internal/interceptors"
+	"github.com/ruziba3vich/mm_article_service/internal/metrics"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
 	"github.com/ruziba3vich/mm_article_service/internal/service"
+	"github.com/ruziba3vich/mm_article_service/internal/sitemap"
 	"github.com/ruziba3vich/mm_article_service/internal/storage"
+	"github.com/ruziba3vich/mm_article_service/internal/tracing"
+	"github.com/ruziba3vich/mm_article_service/internal/webrpc"
 	"github.com/ruziba3vich/mm_article_service/pkg/config"
 	logger "github.com/ruziba3vich/prodonik_lgger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -23,27 +37,89 @@ import (
 )
 
 func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// coreModule provides the configuration, database and repositories every command shares
+var coreModule = fx.Options(
+	fx.Provide(
+		config.LoadConfig,
+		newLogger,
+		storage.NewGORM,
+		idgen.NewUUIDv7,
+		storage.NewArticleRepository,
+		storage.NewFileDbStorage,
+		storage.NewMinIOStorage,
+		storage.NewArchiveStore,
+		storage.NewSitemapStore,
+		service.NewFilesGC,
+		service.NewSitemapGenerator,
+	),
+)
+
+// runServe runs the servers until SIGINT or SIGTERM
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
 	app := fx.New(
+		coreModule,
 		fx.Provide(
-			config.LoadConfig,
-			newLogger,
+			metrics.NewMetrics,
+			tracing.NewTracing,
 			newUserServiceClient,
-			storage.NewGORM,
-			storage.NewArticleRepository,
-			storage.NewFileDbStorage,
-			storage.NewMinIOStorage,
+			storage.NewRedis,
+			storage.NewIdempotencyStore,
 			service.NewArticleService,
+			service.NewLikesReconciler,
+			interceptors.NewIdempotency,
+			interceptors.NewLogging,
+			interceptors.NewAdmin,
+			interceptors.NewClientIP,
+			interceptors.NewRateLimit,
 			newGrpcServer,
+			files.NewHandler,
+			feeds.NewHandler,
+			sitemap.NewHandler,
+			gateway.NewServer,
+			webrpc.NewServer,
 		),
+		fx.Decorate(instrumentMinIO),
+		fx.Invoke(metrics.InstrumentGORM),
+		fx.Invoke(tracing.InstrumentGORM),
 		fx.Invoke(registerHooks),
 	)
 
 	app.Run()
+	return nil
 }
 
 // Create a new gRPC server and register the logging service
-func newGrpcServer(srv *service.ArticleService) *grpc.Server {
-	server := grpc.NewServer()
+func newGrpcServer(
+	srv *service.ArticleService,
+	m *metrics.Metrics,
+	t *tracing.Tracing,
+	logging *interceptors.Logging,
+	admin *interceptors.Admin,
+	rateLimit *interceptors.RateLimit,
+	idempotency *interceptors.Idempotency,
+	cfg *config.Config,
+) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(cfg.MaxMsgBytes),
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(t.Provider()))),
+		grpc.ChainUnaryInterceptor(
+			logging.Unary(),
+			m.UnaryServerInterceptor(),
+			admin.Unary(),
+			rateLimit.Unary(),
+			idempotency.Unary(),
+		),
+	)
 	article_protos.RegisterArticleServiceServer(server, srv)
 	return server
 }
@@ -52,9 +128,21 @@ func newGrpcServer(srv *service.ArticleService) *grpc.Server {
 func registerHooks(
 	lc fx.Lifecycle,
 	db *gorm.DB,
+	rdb *redis.Client,
 	grpcServer *grpc.Server,
+	gatewayServer *gateway.Server,
+	webServer *webrpc.Server,
+	reconciler *service.LikesReconciler,
+	sitemaps *service.SitemapGenerator,
+	idempotency *interceptors.Idempotency,
+	m *metrics.Metrics,
+	t *tracing.Tracing,
 	cfg *config.Config,
 ) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	metricsServer := &http.Server{Addr: fmt.Sprintf(":%s", cfg.MetricsPort), Handler: mux}
+
 	lc.Append(fx.Hook{
 		OnStart: func(context.Context) error {
 			log.Println("Starting article service...")
@@ -72,13 +160,46 @@ func registerHooks(
 				}
 			}()
 
+			if err := gatewayServer.Start(); err != nil {
+				return err
+			}
+			if err := webServer.Start(); err != nil {
+				return err
+			}
+
+			go func() {
+				if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Printf("Metrics server stopped: %v", err)
+				}
+			}()
+			log.Printf("Metrics listening on port %s", cfg.MetricsPort)
+
+			reconciler.Start()
+			sitemaps.Start()
+			idempotency.Start()
+
 			log.Println("Article service started")
 			return nil
 		},
-		OnStop: func(context.Context) error {
+		OnStop: func(ctx context.Context) error {
 			log.Println("Stopping article service...")
 
+			reconciler.Stop()
+			sitemaps.Stop()
+			idempotency.Stop()
+			if err := gatewayServer.Shutdown(ctx); err != nil {
+				log.Printf("Error stopping HTTP gateway: %v", err)
+			}
+			if err := webServer.Shutdown(ctx); err != nil {
+				log.Printf("Error stopping gRPC-Web server: %v", err)
+			}
 			grpcServer.GracefulStop()
+			if err := metricsServer.Shutdown(ctx); err != nil {
+				log.Printf("Error stopping metrics server: %v", err)
+			}
+			if err := t.Shutdown(ctx); err != nil {
+				log.Printf("Error flushing traces: %v", err)
+			}
 			sqlDB, err := db.DB()
 			if err != nil {
 				log.Printf("Error getting raw db connection: %v", err)
@@ -87,6 +208,9 @@ func registerHooks(
 			if err := sqlDB.Close(); err != nil {
 				log.Printf("Error closing database connection: %v", err)
 			}
+			if err := rdb.Close(); err != nil {
+				log.Printf("Error closing redis connection: %v", err)
+			}
 
 			log.Println("Article service stopped")
 			return nil
@@ -103,8 +227,18 @@ func registerHooks(
 	}()
 }
 
-func newUserServiceClient(cfg *config.Config, logger *logger.Logger) (user_protos.UserServiceClient, error) {
-	conn, err := grpc.NewClient(cfg.UserService, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func newUserServiceClient(
+	cfg *config.Config,
+	logger *logger.Logger,
+	m *metrics.Metrics,
+	t *tracing.Tracing,
+	logging *interceptors.Logging,
+) (user_protos.UserServiceClient, error) {
+	conn, err := grpc.NewClient(cfg.UserService,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(t.Provider()))),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClient(), m.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		logger.Error("Failed to connect to User Service", map[string]any{"error": err})
 		return nil, err
@@ -113,6 +247,11 @@ func newUserServiceClient(cfg *config.Config, logger *logger.Logger) (user_proto
 	return user_protos.NewUserServiceClient(conn), nil
 }
 
+// instrumentMinIO wraps the MinIO storage with metrics and tracing
+func instrumentMinIO(s repos.MinIOStorage, m *metrics.Metrics, t *tracing.Tracing) repos.MinIOStorage {
+	return tracing.InstrumentMinIO(metrics.InstrumentMinIO(s, m), t)
+}
+
 func newLogger() (*logger.Logger, error) {
 	return logger.NewLogger("/app/article_service.log")
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/storage"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"go.uber.org/fx"
+)
+
+const migrateUsage = `usage: article_service migrate <command> [--dry-run] [--json]
+
+commands:
+  up            apply all pending migrations
+  down [N]      revert the last N applied migrations (default 1)
+  status        list migrations and whether they are applied
+  to <version>  migrate up or down to exactly <version>`
+
+// migrateModule opens the database without the schema check the other commands run
+var migrateModule = fx.Options(
+	fx.Provide(
+		config.LoadConfig,
+		storage.OpenGORM,
+		storage.NewMigrator,
+	),
+)
+
+type migrateResult struct {
+	DryRun     bool                      `json:"dry_run"`
+	Migrations []storage.MigrationStatus `json:"migrations"`
+}
+
+// runMigrate implements the migrate subcommand
+func runMigrate(args []string) error {
+	fs, out := newFlagSet("migrate", true)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), migrateUsage)
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	var migrate func(m *storage.Migrator, ctx context.Context) ([]storage.MigrationStatus, error)
+	switch args[0] {
+	case "up":
+		migrate = (*storage.Migrator).Up
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		migrate = func(m *storage.Migrator, ctx context.Context) ([]storage.MigrationStatus, error) {
+			return m.Down(ctx, steps)
+		}
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("%s", migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		migrate = func(m *storage.Migrator, ctx context.Context) ([]storage.MigrationStatus, error) {
+			return m.To(ctx, version)
+		}
+	case "status":
+		return runOneShot(migrateModule, func(ctx context.Context, migrator *storage.Migrator) error {
+			statuses, err := migrator.Status(ctx)
+			if err != nil {
+				return err
+			}
+			return out.print(os.Stdout, statuses, func(w io.Writer) {
+				printMigrationStatus(w, statuses)
+			})
+		})
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	}
+
+	return runOneShot(migrateModule, func(ctx context.Context, migrator *storage.Migrator) error {
+		if out.dryRun {
+			migrator = migrator.DryRun()
+		}
+		done, err := migrate(migrator, ctx)
+		// report what was done even when a later migration failed
+		if perr := out.print(os.Stdout, migrateResult{DryRun: out.dryRun, Migrations: done}, func(w io.Writer) {
+			printMigrationResult(w, done, out.dryRun, err == nil)
+		}); perr != nil && err == nil {
+			err = perr
+		}
+		return err
+	})
+}
+
+func printMigrationResult(w io.Writer, done []storage.MigrationStatus, dryRun, succeeded bool) {
+	for _, m := range done {
+		action := "reverted"
+		if m.Applied {
+			action = "applied"
+		}
+		if dryRun {
+			action = "would be " + action
+		}
+		fmt.Fprintf(w, "%s %d_%s\n", action, m.Version, m.Name)
+	}
+	if len(done) == 0 && succeeded {
+		fmt.Fprintln(w, "schema is up to date")
+	}
+}
+
+func printMigrationStatus(out io.Writer, statuses []storage.MigrationStatus) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	w.Flush()
+}
@@ -7,11 +7,22 @@ PROTO_DIR="./protos"
 
 OUT_DIR="./genprotos"
 
-mkdir -p $OUT_DIR
+GATEWAY_CONFIG="./api/article_gateway.yaml"
+
+OPENAPI_DIR="./internal/gateway/openapi"
+
+mkdir -p $OUT_DIR $OPENAPI_DIR
 
 protoc -I=$PROTO_DIR \
   --go_out=$OUT_DIR \
   --go-grpc_out=$OUT_DIR \
+  --grpc-gateway_out=$OUT_DIR \
+  --grpc-gateway_opt=grpc_api_configuration=$GATEWAY_CONFIG \
+  --openapiv2_out=$OPENAPI_DIR \
+  --openapiv2_opt=grpc_api_configuration=$GATEWAY_CONFIG,output_format=json \
   $PROTO_DIR/article_protos/article.proto
 
+mv $OPENAPI_DIR/article_protos/article.swagger.json $OPENAPI_DIR/article.swagger.json
+rmdir $OPENAPI_DIR/article_protos
+
 echo "protos generated successfully"
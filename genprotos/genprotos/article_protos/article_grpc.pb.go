@@ -8,6 +8,7 @@ package article_protos
 
 import (
 	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,15 +20,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ArticleService_CreateArticle_FullMethodName     = "/article_protos.ArticleService/CreateArticle"
-	ArticleService_UpdateArticle_FullMethodName     = "/article_protos.ArticleService/UpdateArticle"
-	ArticleService_RewriteArticle_FullMethodName    = "/article_protos.ArticleService/RewriteArticle"
-	ArticleService_DeleteArticle_FullMethodName     = "/article_protos.ArticleService/DeleteArticle"
-	ArticleService_LikeArticle_FullMethodName       = "/article_protos.ArticleService/LikeArticle"
-	ArticleService_UnlikeArticle_FullMethodName     = "/article_protos.ArticleService/UnlikeArticle"
-	ArticleService_GetArticlesByUser_FullMethodName = "/article_protos.ArticleService/GetArticlesByUser"
-	ArticleService_GetArticles_FullMethodName       = "/article_protos.ArticleService/GetArticles"
-	ArticleService_GetArticleByID_FullMethodName    = "/article_protos.ArticleService/GetArticleByID"
+	ArticleService_CreateArticle_FullMethodName        = "/article_protos.ArticleService/CreateArticle"
+	ArticleService_UpdateArticle_FullMethodName        = "/article_protos.ArticleService/UpdateArticle"
+	ArticleService_RewriteArticle_FullMethodName       = "/article_protos.ArticleService/RewriteArticle"
+	ArticleService_DeleteArticle_FullMethodName        = "/article_protos.ArticleService/DeleteArticle"
+	ArticleService_LikeArticle_FullMethodName          = "/article_protos.ArticleService/LikeArticle"
+	ArticleService_UnlikeArticle_FullMethodName        = "/article_protos.ArticleService/UnlikeArticle"
+	ArticleService_GetArticlesByUser_FullMethodName    = "/article_protos.ArticleService/GetArticlesByUser"
+	ArticleService_GetArticles_FullMethodName          = "/article_protos.ArticleService/GetArticles"
+	ArticleService_GetArticleByID_FullMethodName       = "/article_protos.ArticleService/GetArticleByID"
+	ArticleService_GetArticleBySlug_FullMethodName     = "/article_protos.ArticleService/GetArticleBySlug"
+	ArticleService_ReconcileLikes_FullMethodName       = "/article_protos.ArticleService/ReconcileLikes"
+	ArticleService_ListArticleLikers_FullMethodName    = "/article_protos.ArticleService/ListArticleLikers"
+	ArticleService_ListLikedArticles_FullMethodName    = "/article_protos.ArticleService/ListLikedArticles"
+	ArticleService_AddReaction_FullMethodName          = "/article_protos.ArticleService/AddReaction"
+	ArticleService_RemoveReaction_FullMethodName       = "/article_protos.ArticleService/RemoveReaction"
+	ArticleService_ListArticleRewrites_FullMethodName  = "/article_protos.ArticleService/ListArticleRewrites"
+	ArticleService_GetArticleAncestry_FullMethodName   = "/article_protos.ArticleService/GetArticleAncestry"
+	ArticleService_GetDerivationTree_FullMethodName    = "/article_protos.ArticleService/GetDerivationTree"
+	ArticleService_ImportMarkdownBundle_FullMethodName = "/article_protos.ArticleService/ImportMarkdownBundle"
+	ArticleService_ExportArticle_FullMethodName        = "/article_protos.ArticleService/ExportArticle"
 )
 
 // ArticleServiceClient is the client API for ArticleService service.
@@ -43,6 +55,20 @@ type ArticleServiceClient interface {
 	GetArticlesByUser(ctx context.Context, in *GetArticlesByUserRequest, opts ...grpc.CallOption) (*GetArticlesByUserResponse, error)
 	GetArticles(ctx context.Context, in *GetArticlesRequest, opts ...grpc.CallOption) (*GetArticlesResponse, error)
 	GetArticleByID(ctx context.Context, in *GetArticleByIDRequest, opts ...grpc.CallOption) (*GetArticleByIDResponse, error)
+	GetArticleBySlug(ctx context.Context, in *GetArticleBySlugRequest, opts ...grpc.CallOption) (*GetArticleBySlugResponse, error)
+	ReconcileLikes(ctx context.Context, in *ReconcileLikesRequest, opts ...grpc.CallOption) (*ReconcileLikesResponse, error)
+	ListArticleLikers(ctx context.Context, in *ListArticleLikersRequest, opts ...grpc.CallOption) (*ListArticleLikersResponse, error)
+	ListLikedArticles(ctx context.Context, in *ListLikedArticlesRequest, opts ...grpc.CallOption) (*ListLikedArticlesResponse, error)
+	AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error)
+	RemoveReaction(ctx context.Context, in *RemoveReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error)
+	ListArticleRewrites(ctx context.Context, in *ListArticleRewritesRequest, opts ...grpc.CallOption) (*ListArticleRewritesResponse, error)
+	GetArticleAncestry(ctx context.Context, in *GetArticleAncestryRequest, opts ...grpc.CallOption) (*GetArticleAncestryResponse, error)
+	GetDerivationTree(ctx context.Context, in *GetDerivationTreeRequest, opts ...grpc.CallOption) (*GetDerivationTreeResponse, error)
+	// ImportMarkdownBundle creates a draft for every Markdown file of the uploaded zip archives
+	ImportMarkdownBundle(ctx context.Context, in *ImportMarkdownBundleRequest, opts ...grpc.CallOption) (*ImportMarkdownBundleResponse, error)
+	// ExportArticle renders an article to a downloadable file, the suggested file name is sent in a
+	// content-disposition response header
+	ExportArticle(ctx context.Context, in *ExportArticleRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
 }
 
 type articleServiceClient struct {
@@ -143,6 +169,116 @@ func (c *articleServiceClient) GetArticleByID(ctx context.Context, in *GetArticl
 	return out, nil
 }
 
+func (c *articleServiceClient) GetArticleBySlug(ctx context.Context, in *GetArticleBySlugRequest, opts ...grpc.CallOption) (*GetArticleBySlugResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetArticleBySlugResponse)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticleBySlug_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ReconcileLikes(ctx context.Context, in *ReconcileLikesRequest, opts ...grpc.CallOption) (*ReconcileLikesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconcileLikesResponse)
+	err := c.cc.Invoke(ctx, ArticleService_ReconcileLikes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ListArticleLikers(ctx context.Context, in *ListArticleLikersRequest, opts ...grpc.CallOption) (*ListArticleLikersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListArticleLikersResponse)
+	err := c.cc.Invoke(ctx, ArticleService_ListArticleLikers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ListLikedArticles(ctx context.Context, in *ListLikedArticlesRequest, opts ...grpc.CallOption) (*ListLikedArticlesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLikedArticlesResponse)
+	err := c.cc.Invoke(ctx, ArticleService_ListLikedArticles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReactionResponse)
+	err := c.cc.Invoke(ctx, ArticleService_AddReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) RemoveReaction(ctx context.Context, in *RemoveReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReactionResponse)
+	err := c.cc.Invoke(ctx, ArticleService_RemoveReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ListArticleRewrites(ctx context.Context, in *ListArticleRewritesRequest, opts ...grpc.CallOption) (*ListArticleRewritesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListArticleRewritesResponse)
+	err := c.cc.Invoke(ctx, ArticleService_ListArticleRewrites_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetArticleAncestry(ctx context.Context, in *GetArticleAncestryRequest, opts ...grpc.CallOption) (*GetArticleAncestryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetArticleAncestryResponse)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticleAncestry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetDerivationTree(ctx context.Context, in *GetDerivationTreeRequest, opts ...grpc.CallOption) (*GetDerivationTreeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDerivationTreeResponse)
+	err := c.cc.Invoke(ctx, ArticleService_GetDerivationTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ImportMarkdownBundle(ctx context.Context, in *ImportMarkdownBundleRequest, opts ...grpc.CallOption) (*ImportMarkdownBundleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportMarkdownBundleResponse)
+	err := c.cc.Invoke(ctx, ArticleService_ImportMarkdownBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ExportArticle(ctx context.Context, in *ExportArticleRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, ArticleService_ExportArticle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ArticleServiceServer is the server API for ArticleService service.
 // All implementations must embed UnimplementedArticleServiceServer
 // for forward compatibility.
@@ -156,6 +292,20 @@ type ArticleServiceServer interface {
 	GetArticlesByUser(context.Context, *GetArticlesByUserRequest) (*GetArticlesByUserResponse, error)
 	GetArticles(context.Context, *GetArticlesRequest) (*GetArticlesResponse, error)
 	GetArticleByID(context.Context, *GetArticleByIDRequest) (*GetArticleByIDResponse, error)
+	GetArticleBySlug(context.Context, *GetArticleBySlugRequest) (*GetArticleBySlugResponse, error)
+	ReconcileLikes(context.Context, *ReconcileLikesRequest) (*ReconcileLikesResponse, error)
+	ListArticleLikers(context.Context, *ListArticleLikersRequest) (*ListArticleLikersResponse, error)
+	ListLikedArticles(context.Context, *ListLikedArticlesRequest) (*ListLikedArticlesResponse, error)
+	AddReaction(context.Context, *AddReactionRequest) (*ReactionResponse, error)
+	RemoveReaction(context.Context, *RemoveReactionRequest) (*ReactionResponse, error)
+	ListArticleRewrites(context.Context, *ListArticleRewritesRequest) (*ListArticleRewritesResponse, error)
+	GetArticleAncestry(context.Context, *GetArticleAncestryRequest) (*GetArticleAncestryResponse, error)
+	GetDerivationTree(context.Context, *GetDerivationTreeRequest) (*GetDerivationTreeResponse, error)
+	// ImportMarkdownBundle creates a draft for every Markdown file of the uploaded zip archives
+	ImportMarkdownBundle(context.Context, *ImportMarkdownBundleRequest) (*ImportMarkdownBundleResponse, error)
+	// ExportArticle renders an article to a downloadable file, the suggested file name is sent in a
+	// content-disposition response header
+	ExportArticle(context.Context, *ExportArticleRequest) (*httpbody.HttpBody, error)
 	mustEmbedUnimplementedArticleServiceServer()
 }
 
@@ -193,6 +343,39 @@ func (UnimplementedArticleServiceServer) GetArticles(context.Context, *GetArticl
 func (UnimplementedArticleServiceServer) GetArticleByID(context.Context, *GetArticleByIDRequest) (*GetArticleByIDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetArticleByID not implemented")
 }
+func (UnimplementedArticleServiceServer) GetArticleBySlug(context.Context, *GetArticleBySlugRequest) (*GetArticleBySlugResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticleBySlug not implemented")
+}
+func (UnimplementedArticleServiceServer) ReconcileLikes(context.Context, *ReconcileLikesRequest) (*ReconcileLikesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileLikes not implemented")
+}
+func (UnimplementedArticleServiceServer) ListArticleLikers(context.Context, *ListArticleLikersRequest) (*ListArticleLikersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListArticleLikers not implemented")
+}
+func (UnimplementedArticleServiceServer) ListLikedArticles(context.Context, *ListLikedArticlesRequest) (*ListLikedArticlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLikedArticles not implemented")
+}
+func (UnimplementedArticleServiceServer) AddReaction(context.Context, *AddReactionRequest) (*ReactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReaction not implemented")
+}
+func (UnimplementedArticleServiceServer) RemoveReaction(context.Context, *RemoveReactionRequest) (*ReactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveReaction not implemented")
+}
+func (UnimplementedArticleServiceServer) ListArticleRewrites(context.Context, *ListArticleRewritesRequest) (*ListArticleRewritesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListArticleRewrites not implemented")
+}
+func (UnimplementedArticleServiceServer) GetArticleAncestry(context.Context, *GetArticleAncestryRequest) (*GetArticleAncestryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticleAncestry not implemented")
+}
+func (UnimplementedArticleServiceServer) GetDerivationTree(context.Context, *GetDerivationTreeRequest) (*GetDerivationTreeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDerivationTree not implemented")
+}
+func (UnimplementedArticleServiceServer) ImportMarkdownBundle(context.Context, *ImportMarkdownBundleRequest) (*ImportMarkdownBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportMarkdownBundle not implemented")
+}
+func (UnimplementedArticleServiceServer) ExportArticle(context.Context, *ExportArticleRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportArticle not implemented")
+}
 func (UnimplementedArticleServiceServer) mustEmbedUnimplementedArticleServiceServer() {}
 func (UnimplementedArticleServiceServer) testEmbeddedByValue()                        {}
 
@@ -376,6 +559,204 @@ func _ArticleService_GetArticleByID_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ArticleService_GetArticleBySlug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleBySlugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticleBySlug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticleBySlug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticleBySlug(ctx, req.(*GetArticleBySlugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ReconcileLikes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileLikesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).ReconcileLikes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_ReconcileLikes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).ReconcileLikes(ctx, req.(*ReconcileLikesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ListArticleLikers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArticleLikersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).ListArticleLikers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_ListArticleLikers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).ListArticleLikers(ctx, req.(*ListArticleLikersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ListLikedArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLikedArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).ListLikedArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_ListLikedArticles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).ListLikedArticles(ctx, req.(*ListLikedArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_AddReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).AddReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_AddReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).AddReaction(ctx, req.(*AddReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_RemoveReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).RemoveReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_RemoveReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).RemoveReaction(ctx, req.(*RemoveReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ListArticleRewrites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArticleRewritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).ListArticleRewrites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_ListArticleRewrites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).ListArticleRewrites(ctx, req.(*ListArticleRewritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetArticleAncestry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleAncestryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticleAncestry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticleAncestry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticleAncestry(ctx, req.(*GetArticleAncestryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetDerivationTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDerivationTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetDerivationTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetDerivationTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetDerivationTree(ctx, req.(*GetDerivationTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ImportMarkdownBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportMarkdownBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).ImportMarkdownBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_ImportMarkdownBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).ImportMarkdownBundle(ctx, req.(*ImportMarkdownBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ExportArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).ExportArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_ExportArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).ExportArticle(ctx, req.(*ExportArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ArticleService_ServiceDesc is the grpc.ServiceDesc for ArticleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -419,6 +800,50 @@ var ArticleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetArticleByID",
 			Handler:    _ArticleService_GetArticleByID_Handler,
 		},
+		{
+			MethodName: "GetArticleBySlug",
+			Handler:    _ArticleService_GetArticleBySlug_Handler,
+		},
+		{
+			MethodName: "ReconcileLikes",
+			Handler:    _ArticleService_ReconcileLikes_Handler,
+		},
+		{
+			MethodName: "ListArticleLikers",
+			Handler:    _ArticleService_ListArticleLikers_Handler,
+		},
+		{
+			MethodName: "ListLikedArticles",
+			Handler:    _ArticleService_ListLikedArticles_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _ArticleService_AddReaction_Handler,
+		},
+		{
+			MethodName: "RemoveReaction",
+			Handler:    _ArticleService_RemoveReaction_Handler,
+		},
+		{
+			MethodName: "ListArticleRewrites",
+			Handler:    _ArticleService_ListArticleRewrites_Handler,
+		},
+		{
+			MethodName: "GetArticleAncestry",
+			Handler:    _ArticleService_GetArticleAncestry_Handler,
+		},
+		{
+			MethodName: "GetDerivationTree",
+			Handler:    _ArticleService_GetDerivationTree_Handler,
+		},
+		{
+			MethodName: "ImportMarkdownBundle",
+			Handler:    _ArticleService_ImportMarkdownBundle_Handler,
+		},
+		{
+			MethodName: "ExportArticle",
+			Handler:    _ArticleService_ExportArticle_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "article_protos/article.proto",
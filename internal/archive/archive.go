@@ -0,0 +1,328 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// FormatVersion is bumped whenever a record changes incompatibly, readers refuse newer archives.
+// Version 1 archives only held articles and are still readable
+const FormatVersion = 2
+
+const (
+	// FormatJSONL is a single JSON lines stream holding object references only
+	FormatJSONL = "jsonl"
+	// FormatTar holds manifest.json, records/NNNNNN.jsonl chunks and optionally objects/<file name>
+	FormatTar = "tar"
+)
+
+const (
+	RecordManifest      = "manifest"
+	RecordArticle       = "article"
+	RecordSlug          = "slug"
+	RecordReaction      = "reaction"
+	RecordReactionCount = "reaction_count"
+	RecordPicture       = "picture"
+	// RecordObject is synthesised by Reader for object entries of tar archives
+	RecordObject = "object"
+)
+
+// RecordTypes lists the table record types in the order they are written and restored
+var RecordTypes = []string{RecordArticle, RecordSlug, RecordReaction, RecordReactionCount, RecordPicture}
+
+const (
+	manifestEntry = "manifest.json"
+	recordsDir    = "records/"
+	objectsDir    = "objects/"
+	// maxLineBytes bounds a single record, article bodies are the largest
+	maxLineBytes = 64 << 20
+	// chunkBytes is roughly how much of the records stream a tar archive buffers per entry
+	chunkBytes = 4 << 20
+)
+
+type (
+	// Record is one JSON line of an archive, Type tells how Data decodes
+	Record struct {
+		Type   string          `json:"type"`
+		Data   json.RawMessage `json:"data,omitempty"`
+		Object *Object         `json:"-"`
+	}
+
+	// Object is the content of a stored file, Body must be consumed before the next call to Reader.Next
+	Object struct {
+		Name string
+		Size int64
+		Body io.Reader
+	}
+
+	// Manifest describes an archive, it comes before any record
+	Manifest struct {
+		Version   int       `json:"version"`
+		Format    string    `json:"format,omitempty"`
+		Objects   bool      `json:"objects"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	// Writer streams records, and for tar archives objects, to an archive
+	Writer struct {
+		manifest Manifest
+		closers  []io.Closer
+		out      io.Writer
+
+		// jsonl
+		lines *bufio.Writer
+
+		// tar
+		tw     *tar.Writer
+		chunk  bytes.Buffer
+		chunks int
+	}
+
+	// Reader reads archives written by Writer in either format, gzip compressed or not
+	Reader struct {
+		Manifest Manifest
+
+		lines *bufio.Scanner
+		tr    *tar.Reader
+	}
+)
+
+// NewWriter starts an archive on w in the given format by writing its manifest. Close must be
+// called to flush the archive, it does not close w
+func NewWriter(w io.Writer, format string, objects, compress bool) (*Writer, error) {
+	if format != FormatJSONL && format != FormatTar {
+		return nil, fmt.Errorf("unknown archive format %q", format)
+	}
+	if objects && format != FormatTar {
+		return nil, errors.New("object bytes can only be included in tar archives")
+	}
+
+	aw := &Writer{
+		manifest: Manifest{Version: FormatVersion, Format: format, Objects: objects, CreatedAt: time.Now().UTC()},
+		out:      w,
+	}
+	if compress {
+		gz := gzip.NewWriter(w)
+		aw.out = gz
+		aw.closers = append(aw.closers, gz)
+	}
+
+	if format == FormatTar {
+		aw.tw = tar.NewWriter(aw.out)
+		manifest, err := json.Marshal(aw.manifest)
+		if err != nil {
+			return nil, err
+		}
+		if err := aw.writeEntry(manifestEntry, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+			return nil, err
+		}
+		return aw, nil
+	}
+
+	aw.lines = bufio.NewWriter(aw.out)
+	if err := aw.Write(RecordManifest, aw.manifest); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+// Write appends one record
+func (w *Writer) Write(recordType string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record: %w", recordType, err)
+	}
+	line, err := json.Marshal(Record{Type: recordType, Data: data})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if w.tw == nil {
+		_, err := w.lines.Write(line)
+		return err
+	}
+	w.chunk.Write(line)
+	if w.chunk.Len() >= chunkBytes {
+		return w.flushChunk()
+	}
+	return nil
+}
+
+// WriteObject stores the content of a file, only archives created with objects accept it
+func (w *Writer) WriteObject(name string, size int64, r io.Reader) error {
+	if !w.manifest.Objects {
+		return errors.New("archive was created without objects")
+	}
+	if name == "" || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid object name %q", name)
+	}
+	return w.writeEntry(objectsDir+name, size, r)
+}
+
+// Close flushes buffered records and finishes the archive
+func (w *Writer) Close() error {
+	if w.tw != nil {
+		if err := w.flushChunk(); err != nil {
+			return err
+		}
+		if err := w.tw.Close(); err != nil {
+			return err
+		}
+	} else if err := w.lines.Flush(); err != nil {
+		return err
+	}
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) flushChunk() error {
+	if w.chunk.Len() == 0 {
+		return nil
+	}
+	w.chunks++
+	name := fmt.Sprintf("%s%06d.jsonl", recordsDir, w.chunks)
+	if err := w.writeEntry(name, int64(w.chunk.Len()), &w.chunk); err != nil {
+		return err
+	}
+	w.chunk.Reset()
+	return nil
+}
+
+func (w *Writer) writeEntry(name string, size int64, r io.Reader) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: w.manifest.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	written, err := io.CopyN(w.tw, r, size)
+	if err != nil {
+		return fmt.Errorf("failed to write %s, %d of %d bytes written: %w", name, written, size, err)
+	}
+	return nil
+}
+
+// NewReader detects the format of the archive in r and reads its manifest
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip stream: %w", err)
+		}
+		br = bufio.NewReader(gz)
+	}
+
+	first, err := br.Peek(1)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("archive is empty")
+		}
+		return nil, err
+	}
+
+	ar := &Reader{}
+	if first[0] == '{' {
+		ar.lines = newLineScanner(br)
+		record, err := ar.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("archive is empty")
+			}
+			return nil, err
+		}
+		if record.Type != RecordManifest {
+			return nil, fmt.Errorf("archive starts with a %q record instead of the manifest", record.Type)
+		}
+		if err := json.Unmarshal(record.Data, &ar.Manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+	} else {
+		ar.tr = tar.NewReader(br)
+		header, err := ar.tr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if header.Name != manifestEntry {
+			return nil, fmt.Errorf("tar archive starts with %q instead of %s", header.Name, manifestEntry)
+		}
+		if err := json.NewDecoder(ar.tr).Decode(&ar.Manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+	}
+
+	if ar.Manifest.Version < 1 || ar.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d, this build reads up to %d", ar.Manifest.Version, FormatVersion)
+	}
+	if ar.Manifest.Format == "" {
+		ar.Manifest.Format = FormatJSONL
+	}
+	return ar, nil
+}
+
+// Next returns the next record, io.EOF marks the end of the archive
+func (r *Reader) Next() (*Record, error) {
+	for {
+		if r.lines != nil {
+			for r.lines.Scan() {
+				line := r.lines.Bytes()
+				if len(bytes.TrimSpace(line)) == 0 {
+					continue
+				}
+				var record Record
+				if err := json.Unmarshal(line, &record); err != nil {
+					return nil, fmt.Errorf("invalid archive record: %w", err)
+				}
+				return &record, nil
+			}
+			if err := r.lines.Err(); err != nil {
+				return nil, err
+			}
+			r.lines = nil
+		}
+		if r.tr == nil {
+			return nil, io.EOF
+		}
+
+		header, err := r.tr.Next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case header.Typeflag != tar.TypeReg:
+			continue
+		case strings.HasPrefix(header.Name, recordsDir):
+			r.lines = newLineScanner(r.tr)
+		case strings.HasPrefix(header.Name, objectsDir):
+			name := strings.TrimPrefix(header.Name, objectsDir)
+			if name == "" || path.Clean(name) != name || strings.HasPrefix(name, "../") {
+				return nil, fmt.Errorf("invalid object entry %q", header.Name)
+			}
+			return &Record{Type: RecordObject, Object: &Object{Name: name, Size: header.Size, Body: r.tr}}, nil
+		default:
+			return nil, fmt.Errorf("unexpected tar entry %q", header.Name)
+		}
+	}
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+	return scanner
+}
@@ -0,0 +1,243 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+)
+
+// memoryStore keeps rows keyed like their tables so re-imports conflict the same way
+type memoryStore struct {
+	articles  []*models.Article
+	slugs     []*models.ArticleSlug
+	reactions []*models.ArticleReaction
+	counts    []*models.ArticleReactionCount
+	pictures  []*models.Picture
+	keys      map[string]bool
+}
+
+func (s *memoryStore) Export(_ context.Context, v repos.ArchiveVisitor) error {
+	for _, a := range s.articles {
+		if err := v.Article(a); err != nil {
+			return err
+		}
+	}
+	for _, sl := range s.slugs {
+		if err := v.Slug(sl); err != nil {
+			return err
+		}
+	}
+	for _, r := range s.reactions {
+		if err := v.Reaction(r); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.counts {
+		if err := v.ReactionCount(c); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.pictures {
+		if err := v.Picture(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insert[T any](s *memoryStore, dst *[]*T, rows []*T, key func(*T) string, dryRun bool) int64 {
+	if s.keys == nil {
+		s.keys = map[string]bool{}
+	}
+	var inserted int64
+	for _, row := range rows {
+		k := fmt.Sprintf("%T:%s", row, key(row))
+		if s.keys[k] {
+			continue
+		}
+		inserted++
+		if !dryRun {
+			s.keys[k] = true
+			*dst = append(*dst, row)
+		}
+	}
+	return inserted
+}
+
+func (s *memoryStore) ImportArticles(_ context.Context, rows []*models.Article, dryRun bool) (int64, error) {
+	return insert(s, &s.articles, rows, func(a *models.Article) string { return a.ID }, dryRun), nil
+}
+
+func (s *memoryStore) ImportSlugs(_ context.Context, rows []*models.ArticleSlug, dryRun bool) (int64, error) {
+	return insert(s, &s.slugs, rows, func(a *models.ArticleSlug) string { return a.Slug }, dryRun), nil
+}
+
+func (s *memoryStore) ImportReactions(_ context.Context, rows []*models.ArticleReaction, dryRun bool) (int64, error) {
+	return insert(s, &s.reactions, rows, func(r *models.ArticleReaction) string {
+		return r.UserID + r.ArticleID + r.ReactionType
+	}, dryRun), nil
+}
+
+func (s *memoryStore) ImportReactionCounts(_ context.Context, rows []*models.ArticleReactionCount, dryRun bool) (int64, error) {
+	return insert(s, &s.counts, rows, func(c *models.ArticleReactionCount) string {
+		return c.ArticleID + c.ReactionType
+	}, dryRun), nil
+}
+
+func (s *memoryStore) ImportPictures(_ context.Context, rows []*models.Picture, dryRun bool) (int64, error) {
+	return insert(s, &s.pictures, rows, func(p *models.Picture) string { return p.FileName + p.ArticleID }, dryRun), nil
+}
+
+type memoryFiles struct {
+	objects map[string][]byte
+}
+
+func (f *memoryFiles) CreateFile(context.Context, string, []byte) (string, string, error) {
+	return "", "", nil
+}
+
+func (f *memoryFiles) DeleteFile(context.Context, string) error { return nil }
+
+func (f *memoryFiles) GetFileURL(context.Context, string) (string, error) { return "", nil }
+
+func (f *memoryFiles) PublicURL(name string) string { return "http://files/" + name }
+
+func (f *memoryFiles) WalkFiles(context.Context, func(models.StoredFile) error) error { return nil }
+
+func (f *memoryFiles) OpenFile(_ context.Context, name string) (io.ReadCloser, models.StoredFile, error) {
+	data, ok := f.objects[name]
+	if !ok {
+		return nil, models.StoredFile{}, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), models.StoredFile{Name: name, Size: int64(len(data))}, nil
+}
+
+func (f *memoryFiles) PutFile(_ context.Context, name string, r io.Reader, _ int64) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if f.objects == nil {
+		f.objects = map[string][]byte{}
+	}
+	f.objects[name] = data
+	return nil
+}
+
+func sourceStore() *memoryStore {
+	created := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	return &memoryStore{
+		articles: []*models.Article{
+			{ID: "a-1", UserID: "u-1", Title: "Original", Slug: "original", Content: "body", LikesCount: 1, RewritesCount: 1, CreatedAt: created, ContentRevision: 3},
+			{ID: "a-2", UserID: "u-2", OriginalArticleID: "a-1", Title: "Rewrite", Content: "body 2", CreatedAt: created.Add(time.Hour), ContentRevision: 1},
+		},
+		slugs:     []*models.ArticleSlug{{Slug: "original", ArticleID: "a-1"}, {Slug: "first-title", ArticleID: "a-1"}},
+		reactions: []*models.ArticleReaction{{UserID: "u-2", ArticleID: "a-1", ReactionType: models.ReactionLike, Count: 1, CreatedAt: created}},
+		counts:    []*models.ArticleReactionCount{{ArticleID: "a-1", ReactionType: models.ReactionLike, Total: 1, Reactors: 1}},
+		pictures: []*models.Picture{
+			{FileName: "cover.png", ArticleID: "a-1"},
+			{FileName: "lost.png", ArticleID: "a-2"},
+		},
+	}
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	exported, err := Export(ctx, sourceStore(), nil, &buf, ExportOptions{Format: FormatJSONL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported.Records[RecordArticle] != 2 || exported.Records[RecordReaction] != 1 || exported.Records[RecordPicture] != 2 {
+		t.Fatalf("unexpected export summary %+v", exported)
+	}
+
+	target := &memoryStore{}
+	dry, err := Import(ctx, target, nil, bytes.NewReader(buf.Bytes()), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Imported[RecordArticle] != 2 || len(target.articles) != 0 {
+		t.Fatalf("dry run imported %v and stored %d articles", dry.Imported, len(target.articles))
+	}
+
+	first, err := Import(ctx, target, nil, bytes.NewReader(buf.Bytes()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != FormatVersion || first.Imported[RecordSlug] != 2 || first.Imported[RecordReactionCount] != 1 {
+		t.Fatalf("unexpected first import %+v", first)
+	}
+	rewrite := target.articles[1]
+	if rewrite.ID != "a-2" || rewrite.OriginalArticleID != "a-1" {
+		t.Fatalf("rewrite link not preserved: %+v", rewrite)
+	}
+	if target.articles[0].LikesCount != 1 || target.articles[0].ContentRevision != 3 || target.reactions[0].UserID != "u-2" {
+		t.Fatalf("likes not preserved: %+v %+v", target.articles[0], target.reactions[0])
+	}
+
+	second, err := Import(ctx, target, nil, bytes.NewReader(buf.Bytes()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for recordType, n := range second.Imported {
+		if n != 0 {
+			t.Fatalf("re-import inserted %d %s records", n, recordType)
+		}
+	}
+	if second.Skipped[RecordArticle] != 2 || second.Skipped[RecordPicture] != 2 {
+		t.Fatalf("re-import skipped %v", second.Skipped)
+	}
+}
+
+func TestTarWithObjectsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := &memoryFiles{objects: map[string][]byte{"cover.png": []byte("png-bytes")}}
+
+	var buf bytes.Buffer
+	exported, err := Export(ctx, sourceStore(), source, &buf, ExportOptions{Format: FormatTar, Objects: true, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported.Objects != 1 || exported.ObjectBytes != 9 || exported.MissingObjects != 1 {
+		t.Fatalf("unexpected export summary %+v", exported)
+	}
+
+	target, files := &memoryStore{}, &memoryFiles{}
+	imported, err := Import(ctx, target, files, bytes.NewReader(buf.Bytes()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Format != FormatTar || imported.Objects != 1 || len(target.articles) != 2 || len(target.pictures) != 2 {
+		t.Fatalf("unexpected import summary %+v", imported)
+	}
+	if got := string(files.objects["cover.png"]); got != "png-bytes" {
+		t.Fatalf("restored object = %q", got)
+	}
+}
+
+func TestArchiveValidation(t *testing.T) {
+	if _, err := NewWriter(io.Discard, FormatJSONL, true, false); err == nil {
+		t.Fatal("objects were accepted in a JSONL archive")
+	}
+	archive := `{"type":"manifest","data":{"version":99}}` + "\n"
+	if _, err := NewReader(strings.NewReader(archive)); err == nil || !strings.Contains(err.Error(), "unsupported archive version") {
+		t.Fatalf("err = %v, want unsupported version", err)
+	}
+	if _, err := NewReader(strings.NewReader(`{"type":"article","data":{}}`)); err == nil {
+		t.Fatal("archive without manifest was accepted")
+	}
+
+	v1 := `{"type":"manifest","data":{"version":1}}` + "\n" + `{"type":"article","data":{"id":"a-1"}}` + "\n"
+	if _, err := Import(context.Background(), &memoryStore{}, nil, strings.NewReader(v1), false); err == nil || !strings.Contains(err.Error(), "user_id") {
+		t.Fatalf("err = %v, want article validation error", err)
+	}
+}
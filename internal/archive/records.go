@@ -0,0 +1,214 @@
+package archive
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
+
+// The archived forms of the tables are decoupled from the models so column renames do not change
+// the archive format
+type (
+	articleRecord struct {
+		ID                 string    `json:"id"`
+		UserID             string    `json:"user_id"`
+		OriginalArticleID  string    `json:"original_article_id,omitempty"`
+		Title              string    `json:"title"`
+		Slug               string    `json:"slug,omitempty"`
+		Content            string    `json:"content"`
+		ContentFormat      string    `json:"content_format"`
+		ContentHTML        string    `json:"content_html"`
+		WordCount          int       `json:"word_count"`
+		ReadingTimeMinutes int       `json:"reading_time_minutes"`
+		Excerpt            string    `json:"excerpt"`
+		CreatedAt          time.Time `json:"created_at"`
+		UpdatedAt          time.Time `json:"updated_at"`
+		EditedAt           time.Time `json:"edited_at"`
+		LikesCount         int       `json:"likes_count"`
+		RewritesCount      int       `json:"rewrites_count"`
+		RewritePolicy      string    `json:"rewrite_policy"`
+		License            string    `json:"license"`
+		Attribution        string    `json:"attribution"`
+		ContentRevision    int64     `json:"content_revision"`
+		// Status and Tags are missing from archives written before drafts existed
+		Status string   `json:"status,omitempty"`
+		Tags   []string `json:"tags,omitempty"`
+	}
+
+	// Base is missing from archives written before slugs recorded it
+	slugRecord struct {
+		Slug      string    `json:"slug"`
+		ArticleID string    `json:"article_id"`
+		Base      string    `json:"base,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	reactionRecord struct {
+		UserID       string    `json:"user_id"`
+		ArticleID    string    `json:"article_id"`
+		ReactionType string    `json:"reaction_type"`
+		Count        int       `json:"count"`
+		CreatedAt    time.Time `json:"created_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+
+	reactionCountRecord struct {
+		ArticleID    string `json:"article_id"`
+		ReactionType string `json:"reaction_type"`
+		Total        int    `json:"total"`
+		Reactors     int    `json:"reactors"`
+	}
+
+	pictureRecord struct {
+		FileName  string `json:"file_name"`
+		ArticleID string `json:"article_id"`
+	}
+)
+
+func toArticleRecord(a *models.Article) articleRecord {
+	return articleRecord{
+		ID:                 a.ID,
+		UserID:             a.UserID,
+		OriginalArticleID:  a.OriginalArticleID,
+		Title:              a.Title,
+		Slug:               a.Slug,
+		Content:            a.Content,
+		ContentFormat:      a.ContentFormat,
+		ContentHTML:        a.ContentHTML,
+		WordCount:          a.WordCount,
+		ReadingTimeMinutes: a.ReadingTimeMinutes,
+		Excerpt:            a.Excerpt,
+		CreatedAt:          a.CreatedAt,
+		UpdatedAt:          a.UpdatedAt,
+		EditedAt:           a.EditedAt,
+		LikesCount:         a.LikesCount,
+		RewritesCount:      a.RewritesCount,
+		RewritePolicy:      a.RewritePolicy,
+		License:            a.License,
+		Attribution:        a.Attribution,
+		ContentRevision:    a.ContentRevision,
+		Status:             a.Status,
+		Tags:               a.Tags,
+	}
+}
+
+func (r articleRecord) toModel() *models.Article {
+	articleStatus := r.Status
+	if articleStatus == "" {
+		articleStatus = models.ArticlePublished
+	}
+	editedAt := r.EditedAt
+	if editedAt.IsZero() {
+		editedAt = r.UpdatedAt
+	}
+	return &models.Article{
+		ID:                 r.ID,
+		UserID:             r.UserID,
+		OriginalArticleID:  r.OriginalArticleID,
+		Title:              r.Title,
+		Slug:               r.Slug,
+		Content:            r.Content,
+		ContentFormat:      r.ContentFormat,
+		ContentHTML:        r.ContentHTML,
+		WordCount:          r.WordCount,
+		ReadingTimeMinutes: r.ReadingTimeMinutes,
+		Excerpt:            r.Excerpt,
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+		EditedAt:           editedAt,
+		LikesCount:         r.LikesCount,
+		RewritesCount:      r.RewritesCount,
+		RewritePolicy:      r.RewritePolicy,
+		License:            r.License,
+		Attribution:        r.Attribution,
+		ContentRevision:    r.ContentRevision,
+		Status:             articleStatus,
+		Tags:               r.Tags,
+	}
+}
+
+func toSlugRecord(s *models.ArticleSlug) slugRecord {
+	return slugRecord{Slug: s.Slug, ArticleID: s.ArticleID, Base: s.Base, CreatedAt: s.CreatedAt}
+}
+
+func (r slugRecord) toModel() *models.ArticleSlug {
+	base := r.Base
+	if base == "" {
+		base = r.Slug
+	}
+	return &models.ArticleSlug{Slug: r.Slug, ArticleID: r.ArticleID, Base: base, CreatedAt: r.CreatedAt}
+}
+
+func toReactionRecord(r *models.ArticleReaction) reactionRecord {
+	return reactionRecord{
+		UserID:       r.UserID,
+		ArticleID:    r.ArticleID,
+		ReactionType: r.ReactionType,
+		Count:        r.Count,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+func (r reactionRecord) toModel() *models.ArticleReaction {
+	return &models.ArticleReaction{
+		UserID:       r.UserID,
+		ArticleID:    r.ArticleID,
+		ReactionType: r.ReactionType,
+		Count:        r.Count,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+func toReactionCountRecord(c *models.ArticleReactionCount) reactionCountRecord {
+	return reactionCountRecord{ArticleID: c.ArticleID, ReactionType: c.ReactionType, Total: c.Total, Reactors: c.Reactors}
+}
+
+func (r reactionCountRecord) toModel() *models.ArticleReactionCount {
+	return &models.ArticleReactionCount{ArticleID: r.ArticleID, ReactionType: r.ReactionType, Total: r.Total, Reactors: r.Reactors}
+}
+
+func toPictureRecord(p *models.Picture) pictureRecord {
+	return pictureRecord{FileName: p.FileName, ArticleID: p.ArticleID}
+}
+
+func (r pictureRecord) toModel() *models.Picture {
+	return &models.Picture{FileName: r.FileName, ArticleID: r.ArticleID}
+}
+
+func (r articleRecord) validate() error {
+	if r.ID == "" || r.UserID == "" {
+		return errors.New("id and user_id are required")
+	}
+	return nil
+}
+
+func (r slugRecord) validate() error {
+	if r.Slug == "" || r.ArticleID == "" {
+		return errors.New("slug and article_id are required")
+	}
+	return nil
+}
+
+func (r reactionRecord) validate() error {
+	if r.UserID == "" || r.ArticleID == "" || r.ReactionType == "" {
+		return errors.New("user_id, article_id and reaction_type are required")
+	}
+	return nil
+}
+
+func (r reactionCountRecord) validate() error {
+	if r.ArticleID == "" || r.ReactionType == "" {
+		return errors.New("article_id and reaction_type are required")
+	}
+	return nil
+}
+
+func (r pictureRecord) validate() error {
+	if r.FileName == "" || r.ArticleID == "" {
+		return errors.New("file_name and article_id are required")
+	}
+	return nil
+}
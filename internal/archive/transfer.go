@@ -0,0 +1,241 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+)
+
+const importBatchSize = 500
+
+type (
+	// ExportOptions controls the shape of an archive, Objects needs FormatTar
+	ExportOptions struct {
+		Format   string
+		Objects  bool
+		Compress bool
+	}
+
+	// ExportSummary counts what an export wrote, per record type
+	ExportSummary struct {
+		Format         string           `json:"format"`
+		Records        map[string]int64 `json:"records"`
+		Objects        int64            `json:"objects"`
+		ObjectBytes    int64            `json:"object_bytes"`
+		MissingObjects int64            `json:"missing_objects"`
+	}
+
+	// ImportSummary counts what an import read and how much of it was new, per record type
+	ImportSummary struct {
+		Version  int              `json:"version"`
+		Format   string           `json:"format"`
+		Records  map[string]int64 `json:"records"`
+		Imported map[string]int64 `json:"imported"`
+		Skipped  map[string]int64 `json:"skipped"`
+		Objects  int64            `json:"objects"`
+		DryRun   bool             `json:"dry_run"`
+	}
+
+	// modelRecord is the archived form of a row of T
+	modelRecord[T any] interface {
+		validate() error
+		toModel() *T
+	}
+
+	// batch buffers decoded rows of one table until they are inserted together
+	batch[T any] struct {
+		recordType string
+		rows       []*T
+		insert     func(context.Context, []*T, bool) (int64, error)
+	}
+)
+
+// Export streams every archived table from store, and with opts.Objects the bytes of every
+// picture from files, into a new archive on w. files is only used when objects are included
+func Export(ctx context.Context, store repos.ArchiveStore, files repos.MinIOStorage, w io.Writer, opts ExportOptions) (*ExportSummary, error) {
+	aw, err := NewWriter(w, opts.Format, opts.Objects, opts.Compress)
+	if err != nil {
+		return nil, err
+	}
+	summary := &ExportSummary{Format: opts.Format, Records: map[string]int64{}}
+	write := func(recordType string, v any) error {
+		summary.Records[recordType]++
+		return aw.Write(recordType, v)
+	}
+
+	written := map[string]bool{}
+	exportObject := func(name string) error {
+		if written[name] {
+			return nil
+		}
+		written[name] = true
+		body, info, err := files.OpenFile(ctx, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			summary.MissingObjects++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read object %s: %w", name, err)
+		}
+		defer body.Close()
+		if err := aw.WriteObject(name, info.Size, body); err != nil {
+			return err
+		}
+		summary.Objects++
+		summary.ObjectBytes += info.Size
+		return nil
+	}
+
+	err = store.Export(ctx, repos.ArchiveVisitor{
+		Article: func(a *models.Article) error { return write(RecordArticle, toArticleRecord(a)) },
+		Slug:    func(s *models.ArticleSlug) error { return write(RecordSlug, toSlugRecord(s)) },
+		Reaction: func(r *models.ArticleReaction) error {
+			return write(RecordReaction, toReactionRecord(r))
+		},
+		ReactionCount: func(c *models.ArticleReactionCount) error {
+			return write(RecordReactionCount, toReactionCountRecord(c))
+		},
+		Picture: func(p *models.Picture) error {
+			if err := write(RecordPicture, toPictureRecord(p)); err != nil {
+				return err
+			}
+			if !opts.Objects {
+				return nil
+			}
+			return exportObject(p.FileName)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Import restores the archive in r into store and files. Keys are preserved and rows that already
+// exist are skipped, objects are rewritten under their original names, so an archive can be imported
+// again after a partial failure
+func Import(ctx context.Context, store repos.ArchiveStore, files repos.MinIOStorage, r io.Reader, dryRun bool) (*ImportSummary, error) {
+	ar, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	summary := &ImportSummary{
+		Version:  ar.Manifest.Version,
+		Format:   ar.Manifest.Format,
+		Records:  map[string]int64{},
+		Imported: map[string]int64{},
+		Skipped:  map[string]int64{},
+		DryRun:   dryRun,
+	}
+
+	articles := &batch[models.Article]{recordType: RecordArticle, insert: store.ImportArticles}
+	slugs := &batch[models.ArticleSlug]{recordType: RecordSlug, insert: store.ImportSlugs}
+	reactions := &batch[models.ArticleReaction]{recordType: RecordReaction, insert: store.ImportReactions}
+	counts := &batch[models.ArticleReactionCount]{recordType: RecordReactionCount, insert: store.ImportReactionCounts}
+	pictures := &batch[models.Picture]{recordType: RecordPicture, insert: store.ImportPictures}
+
+	for {
+		record, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Type == RecordObject {
+			if err := importObject(ctx, files, record.Object, dryRun); err != nil {
+				return nil, err
+			}
+			summary.Objects++
+			continue
+		}
+
+		summary.Records[record.Type]++
+		switch record.Type {
+		case RecordArticle:
+			err = addRecord[articleRecord](ctx, articles, summary, record, dryRun)
+		case RecordSlug:
+			err = addRecord[slugRecord](ctx, slugs, summary, record, dryRun)
+		case RecordReaction:
+			err = addRecord[reactionRecord](ctx, reactions, summary, record, dryRun)
+		case RecordReactionCount:
+			err = addRecord[reactionCountRecord](ctx, counts, summary, record, dryRun)
+		case RecordPicture:
+			err = addRecord[pictureRecord](ctx, pictures, summary, record, dryRun)
+		default:
+			err = fmt.Errorf("unknown archive record type %q", record.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, flush := range []func() error{
+		func() error { return articles.flush(ctx, summary, dryRun) },
+		func() error { return slugs.flush(ctx, summary, dryRun) },
+		func() error { return reactions.flush(ctx, summary, dryRun) },
+		func() error { return counts.flush(ctx, summary, dryRun) },
+		func() error { return pictures.flush(ctx, summary, dryRun) },
+	} {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+func importObject(ctx context.Context, files repos.MinIOStorage, object *Object, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if files == nil {
+		return errors.New("archive contains objects but no file storage is configured")
+	}
+	if err := files.PutFile(ctx, object.Name, object.Body, object.Size); err != nil {
+		return fmt.Errorf("failed to restore object %s: %w", object.Name, err)
+	}
+	return nil
+}
+
+// addRecord decodes and validates one record and queues its row for insertion
+func addRecord[R modelRecord[T], T any](ctx context.Context, b *batch[T], summary *ImportSummary, record *Record, dryRun bool) error {
+	var rec R
+	if err := json.Unmarshal(record.Data, &rec); err != nil {
+		return fmt.Errorf("invalid %s record: %w", record.Type, err)
+	}
+	if err := rec.validate(); err != nil {
+		return fmt.Errorf("invalid %s record: %w", record.Type, err)
+	}
+	return b.add(ctx, summary, rec.toModel(), dryRun)
+}
+
+func (b *batch[T]) add(ctx context.Context, summary *ImportSummary, row *T, dryRun bool) error {
+	b.rows = append(b.rows, row)
+	if len(b.rows) < importBatchSize {
+		return nil
+	}
+	return b.flush(ctx, summary, dryRun)
+}
+
+func (b *batch[T]) flush(ctx context.Context, summary *ImportSummary, dryRun bool) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+	inserted, err := b.insert(ctx, b.rows, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to import %s records: %w", b.recordType, err)
+	}
+	summary.Imported[b.recordType] += inserted
+	summary.Skipped[b.recordType] += int64(len(b.rows)) - inserted
+	b.rows = b.rows[:0]
+	return nil
+}
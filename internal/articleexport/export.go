@@ -0,0 +1,216 @@
+// Package articleexport renders an article to files authors can keep: a single HTML document with
+// its images inlined, or its Markdown source with the images next to it in a zip
+package articleexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/mdbundle"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	HTMLContentType = "text/html; charset=utf-8"
+	ZipContentType  = "application/zip"
+
+	// imagesDir is where the Markdown export keeps images, relative to article.md
+	imagesDir = "images/"
+)
+
+// imgSrc matches the src attribute of rendered img tags, the sanitiser always double quotes attributes
+var imgSrc = regexp.MustCompile(`(<img\b[^>]*?\bsrc=")([^"]*)(")`)
+
+var page = template.Must(template.New("article").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{- if .Author}}
+<meta name="author" content="{{.Author}}">
+{{- end}}
+<style>
+body { max-width: 42rem; margin: 2rem auto; padding: 0 1rem; font: 18px/1.6 Georgia, serif; color: #242424; }
+h1 { font-size: 2.2rem; line-height: 1.2; margin-bottom: .5rem; }
+.byline, footer { color: #6b6b6b; font-size: .9rem; }
+img { max-width: 100%; height: auto; }
+pre { overflow-x: auto; background: #f2f2f2; padding: 1rem; }
+</style>
+</head>
+<body>
+<article>
+<h1>{{.Title}}</h1>
+<p class="byline">{{if .Author}}{{.Author}} · {{end}}<time datetime="{{.Date.Format "2006-01-02T15:04:05Z07:00"}}">{{.Date.Format "2 January 2006"}}</time></p>
+{{.Content}}
+{{- range .Extra}}
+<figure><img src="{{.}}" alt=""></figure>
+{{- end}}
+<footer>{{.License}}{{if .Attribution}} · {{.Attribution}}{{end}}</footer>
+</article>
+</body>
+</html>
+`))
+
+type (
+	// Image is an object attached to the article, URL is how the article body links to it
+	Image struct {
+		Name string
+		URL  string
+		Data []byte
+	}
+
+	pageData struct {
+		Title       string
+		Author      string
+		Date        time.Time
+		Content     template.HTML
+		Extra       []template.URL
+		License     string
+		Attribution string
+	}
+
+	frontMatter struct {
+		Title       string    `yaml:"title"`
+		Author      string    `yaml:"author,omitempty"`
+		Date        time.Time `yaml:"date"`
+		Tags        []string  `yaml:"tags,omitempty"`
+		License     string    `yaml:"license,omitempty"`
+		Attribution string    `yaml:"attribution,omitempty"`
+	}
+)
+
+// FileName suggests a name for the exported file, based on the slug when there is one
+func FileName(article *article_protos.ArticleEntity, ext string) string {
+	name := article.Slug
+	if name == "" {
+		name = article.Id
+	}
+	return name + ext
+}
+
+// HTML renders article as a standalone page, images the body links to are replaced by data URIs
+// and attached images it does not mention are appended as figures
+func HTML(article *article_protos.ArticleEntity, images []Image) ([]byte, error) {
+	inline := make(map[string]string, len(images))
+	for _, img := range images {
+		inline[img.URL] = dataURI(img)
+	}
+
+	used := map[string]bool{}
+	content := imgSrc.ReplaceAllStringFunc(article.ContentHtml, func(tag string) string {
+		m := imgSrc.FindStringSubmatch(tag)
+		uri, ok := inline[m[2]]
+		if !ok {
+			return tag
+		}
+		used[m[2]] = true
+		return m[1] + uri + m[3]
+	})
+
+	data := pageData{
+		Title:       article.Title,
+		Author:      author(article),
+		Date:        article.CreatedAt.AsTime(),
+		Content:     template.HTML(content), // sanitised when the article was saved
+		License:     license(article),
+		Attribution: article.Attribution,
+	}
+	for _, img := range images {
+		if !used[img.URL] {
+			data.Extra = append(data.Extra, template.URL(inline[img.URL]))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarkdownZip packs article.md, the source with YAML front matter the Markdown import understands,
+// and the images under images/ with the body's links pointing at them
+func MarkdownZip(article *article_protos.ArticleEntity, images []Image) ([]byte, error) {
+	local := make(map[string]string, len(images))
+	for _, img := range images {
+		local[img.URL] = imagesDir + img.Name
+	}
+	used := map[string]bool{}
+	body := mdbundle.RewriteImages(article.Content, func(ref string) (string, bool) {
+		target, ok := local[ref]
+		used[ref] = ok || used[ref]
+		return target, ok
+	})
+	for _, img := range images {
+		if !used[img.URL] {
+			body += "\n\n![](" + imagesDir + img.Name + ")"
+		}
+	}
+
+	fm, err := yaml.Marshal(frontMatter{
+		Title:       article.Title,
+		Author:      author(article),
+		Date:        article.CreatedAt.AsTime(),
+		Tags:        article.Tags,
+		License:     license(article),
+		Attribution: article.Attribution,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("article.md")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte("---\n" + string(fm) + "---\n\n" + strings.TrimSpace(body) + "\n")); err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		// images are already compressed, storing them saves the effort
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: path.Join(imagesDir, img.Name), Method: zip.Store})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(img.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func author(article *article_protos.ArticleEntity) string {
+	if article.UserFullName != "" {
+		return article.UserFullName
+	}
+	return article.UserUsername
+}
+
+func license(article *article_protos.ArticleEntity) string {
+	name, _ := models.LicenseFromProto(article.License)
+	return models.LicenseName(name)
+}
+
+func dataURI(img Image) string {
+	contentType := mime.TypeByExtension(path.Ext(img.Name))
+	if contentType == "" {
+		contentType = http.DetectContentType(img.Data)
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+}
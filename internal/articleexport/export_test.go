@@ -0,0 +1,102 @@
+package articleexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/mdbundle"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testArticle() (*article_protos.ArticleEntity, []Image) {
+	article := &article_protos.ArticleEntity{
+		Id:           "a-1",
+		Slug:         "hello-world",
+		Title:        "Hello <World>",
+		Content:      "Intro\n\n![cover](http://files/cover.png)\n\n![remote](https://example.com/x.png)",
+		ContentHtml:  `<p>Intro</p><p><img src="http://files/cover.png" alt="cover"></p><p><img src="https://example.com/x.png" alt="remote"></p>`,
+		UserFullName: "Ali Valiyev",
+		CreatedAt:    timestamppb.New(time.Date(2024, 2, 3, 10, 0, 0, 0, time.UTC)),
+		Tags:         []string{"go", "web"},
+		License:      article_protos.License_LICENSE_CC_BY,
+	}
+	images := []Image{
+		{Name: "cover.png", URL: "http://files/cover.png", Data: []byte("cover")},
+		{Name: "extra.jpg", URL: "http://files/extra.jpg", Data: []byte("extra")},
+	}
+	return article, images
+}
+
+func TestHTML(t *testing.T) {
+	article, images := testArticle()
+	out, err := HTML(article, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(out)
+	for _, want := range []string{
+		"<title>Hello &lt;World&gt;</title>",
+		"Ali Valiyev",
+		`<time datetime="2024-02-03T10:00:00Z">3 February 2024</time>`,
+		`<img src="data:image/png;base64,Y292ZXI=" alt="cover">`,
+		`<img src="https://example.com/x.png" alt="remote">`,
+		`<figure><img src="data:image/jpeg;base64,ZXh0cmE=" alt=""></figure>`,
+		"CC BY 4.0",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %s:\n%s", want, page)
+		}
+	}
+	if FileName(article, ".html") != "hello-world.html" {
+		t.Errorf("file name = %s", FileName(article, ".html"))
+	}
+}
+
+func TestMarkdownZipRoundTripsThroughImport(t *testing.T) {
+	article, images := testArticle()
+	out, err := MarkdownZip(article, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	if files["images/cover.png"] != "cover" || files["images/extra.jpg"] != "extra" {
+		t.Fatalf("images = %v", files)
+	}
+	md := files["article.md"]
+	for _, want := range []string{"![cover](images/cover.png)", "![remote](https://example.com/x.png)", "![](images/extra.jpg)", "author: Ali Valiyev"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("article.md lacks %s:\n%s", want, md)
+		}
+	}
+
+	bundle, err := mdbundle.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := bundle.Documents[0]
+	if doc.Title != article.Title || !doc.Date.Equal(article.CreatedAt.AsTime()) || strings.Join(doc.Tags, ",") != "go,web" {
+		t.Errorf("imported document = %+v", doc)
+	}
+	if refs, warnings := bundle.Images(doc); len(refs) != 2 || len(warnings) != 0 {
+		t.Errorf("images = %v, warnings = %v", refs, warnings)
+	}
+}
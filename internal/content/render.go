@@ -0,0 +1,75 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+var ErrUnknownFormat = errors.New("unknown content format")
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	// policy is the single allow-list every rendered body passes through, whatever its source format.
+	// It drops scripts, event handler attributes, inline styles and any URL scheme other than http, https and mailto.
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render converts source in the given format to sanitised HTML
+func Render(format, source string) (string, error) {
+	var rendered string
+	switch format {
+	case FormatPlain:
+		rendered = renderPlain(source)
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(source), &buf); err != nil {
+			return "", err
+		}
+		rendered = buf.String()
+	case FormatHTML:
+		rendered = source
+	default:
+		return "", ErrUnknownFormat
+	}
+	return policy.Sanitize(rendered), nil
+}
+
+// renderPlain escapes text and keeps its paragraph and line structure
+func renderPlain(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	var sb strings.Builder
+	for _, paragraph := range strings.Split(source, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i := range lines {
+			lines[i] = html.EscapeString(lines[i])
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(strings.Join(lines, "<br>"))
+		sb.WriteString("</p>\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,138 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// xssCorpus holds payloads that must never survive rendering in any format
+var xssCorpus = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=https://evil.example/xss.js></SCRIPT>`,
+	`<img src=x onerror=alert(1)>`,
+	`<img src="javascript:alert(1)">`,
+	`<body onload=alert(1)>`,
+	`<svg onload=alert(1)><circle r="10"/></svg>`,
+	`<a href="javascript:alert(1)">click</a>`,
+	`<a href="JaVaScRiPt:alert(1)">click</a>`,
+	`<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">click</a>`,
+	`<a href="  javascript:alert(1)">click</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">click</a>`,
+	`<a href="vbscript:msgbox(1)">click</a>`,
+	`<iframe src="https://evil.example"></iframe>`,
+	`<object data="https://evil.example/x.swf"></object>`,
+	`<embed src="https://evil.example/x.swf">`,
+	`<div style="background:url(javascript:alert(1))">x</div>`,
+	`<p onclick="alert(1)" onmouseover="alert(1)">x</p>`,
+	`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+	`<form action="javascript:alert(1)"><input type="submit"></form>`,
+	`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+	`<style>body{background:url(javascript:alert(1))}</style>`,
+	`<base href="javascript:alert(1)//">`,
+	`[click](javascript:alert(1))`,
+	`[click](JAVASCRIPT:alert(1))`,
+	`![img](javascript:alert(1))`,
+	`[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)`,
+	`<details open ontoggle=alert(1)>`,
+	"```html\n<script>alert(1)</script>\n```",
+}
+
+var (
+	// forbiddenElements must never appear as live markup
+	forbiddenElements = map[string]bool{
+		"script": true, "iframe": true, "object": true, "embed": true, "svg": true, "math": true,
+		"style": true, "meta": true, "base": true, "form": true, "input": true,
+	}
+	// urlAttributes are checked for their scheme
+	urlAttributes  = map[string]bool{"href": true, "src": true, "action": true, "xlink:href": true}
+	allowedSchemes = []string{"http:", "https:", "mailto:"}
+)
+
+// assertSafe parses rendered HTML and fails on any element, attribute or URL that could execute script
+func assertSafe(t *testing.T, format, payload, out string) {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Render(%s, %q) produced unparseable HTML: %v", format, payload, err)
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if forbiddenElements[n.Data] {
+				t.Errorf("Render(%s, %q) = %q, contains <%s>", format, payload, out, n.Data)
+			}
+			for _, attr := range n.Attr {
+				key := strings.ToLower(attr.Key)
+				if attr.Namespace != "" {
+					key = attr.Namespace + ":" + key
+				}
+				if strings.HasPrefix(key, "on") || key == "style" {
+					t.Errorf("Render(%s, %q) = %q, keeps attribute %s", format, payload, out, key)
+				}
+				if urlAttributes[key] {
+					value := strings.ToLower(strings.TrimSpace(attr.Val))
+					if i := strings.Index(value, ":"); i >= 0 && !strings.ContainsAny(value[:i], "/?#") && !hasAllowedScheme(value) {
+						t.Errorf("Render(%s, %q) = %q, keeps %s=%q", format, payload, out, key, attr.Val)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+}
+
+func hasAllowedScheme(value string) bool {
+	for _, scheme := range allowedSchemes {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRenderStripsXSS(t *testing.T) {
+	for _, format := range []string{FormatPlain, FormatMarkdown, FormatHTML} {
+		for _, payload := range xssCorpus {
+			out, err := Render(format, payload)
+			if err != nil {
+				t.Fatalf("Render(%s, %q) returned error: %v", format, payload, err)
+			}
+			assertSafe(t, format, payload, out)
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	out, err := Render(FormatMarkdown, "# Title\n\nSome *emphasis* and a [link](https://example.com).")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h1", "Title</h1>", "<em>emphasis</em>", `href="https://example.com"`, `rel="nofollow noopener"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rendered markdown %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestRenderPlainEscapes(t *testing.T) {
+	out, err := Render(FormatPlain, "a < b\nline two\n\nsecond paragraph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<p>a &lt; b<br>line two</p>\n<p>second paragraph</p>\n"
+	if out != want {
+		t.Errorf("Render(plain) = %q, want %q", out, want)
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if _, err := Render("rtf", "x"); err != ErrUnknownFormat {
+		t.Errorf("Render(rtf) error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,76 @@
+package content
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	wordsPerMinute = 200
+	excerptLength  = 280
+)
+
+// Stats is reading metadata derived from a rendered article body
+type Stats struct {
+	WordCount          int
+	ReadingTimeMinutes int
+	Excerpt            string
+}
+
+// Analyze derives word count, reading time and a plain-text excerpt from rendered HTML
+func Analyze(rendered string) Stats {
+	words := strings.Fields(PlainText(rendered))
+	stats := Stats{
+		WordCount: len(words),
+		Excerpt:   excerpt(words),
+	}
+	if stats.WordCount > 0 {
+		stats.ReadingTimeMinutes = int(math.Ceil(float64(stats.WordCount) / wordsPerMinute))
+	}
+	return stats
+}
+
+// PlainText returns the text nodes of an HTML fragment separated by whitespace
+func PlainText(fragment string) string {
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return sb.String()
+		case html.TextToken:
+			sb.Write(tokenizer.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			// block boundaries must not glue neighbouring words together
+			sb.WriteByte(' ')
+		}
+	}
+}
+
+// excerpt joins leading words up to excerptLength runes, cutting on a word boundary
+func excerpt(words []string) string {
+	var sb strings.Builder
+	length := 0
+	for i, word := range words {
+		wordLength := utf8.RuneCountInString(word)
+		if i > 0 {
+			wordLength++
+		}
+		if length+wordLength > excerptLength {
+			if length == 0 {
+				// a single overlong word is cut mid-word
+				return string([]rune(word)[:excerptLength]) + "…"
+			}
+			return sb.String() + "…"
+		}
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(word)
+		length += wordLength
+	}
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+package content
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAnalyze(t *testing.T) {
+	rendered, err := Render(FormatMarkdown, "# Hello world\n\nThis is **bold**<br>text.\n\n- one\n- two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := Analyze(rendered)
+	if stats.WordCount != 8 {
+		t.Errorf("WordCount = %d, want 8", stats.WordCount)
+	}
+	if stats.ReadingTimeMinutes != 1 {
+		t.Errorf("ReadingTimeMinutes = %d, want 1", stats.ReadingTimeMinutes)
+	}
+	if stats.Excerpt != "Hello world This is bold text. one two" {
+		t.Errorf("Excerpt = %q", stats.Excerpt)
+	}
+}
+
+func TestAnalyzeLongText(t *testing.T) {
+	stats := Analyze("<p>" + strings.Repeat("слово ", 450) + "</p>")
+	if stats.WordCount != 450 {
+		t.Errorf("WordCount = %d, want 450", stats.WordCount)
+	}
+	if stats.ReadingTimeMinutes != 3 {
+		t.Errorf("ReadingTimeMinutes = %d, want 3", stats.ReadingTimeMinutes)
+	}
+	if !strings.HasSuffix(stats.Excerpt, "…") || utf8.RuneCountInString(stats.Excerpt) > excerptLength+1 {
+		t.Errorf("Excerpt = %q, want at most %d runes ending in an ellipsis", stats.Excerpt, excerptLength)
+	}
+}
+
+func TestAnalyzeEmpty(t *testing.T) {
+	if stats := Analyze(""); stats != (Stats{}) {
+		t.Errorf("Analyze(\"\") = %+v, want zero value", stats)
+	}
+}
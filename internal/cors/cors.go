@@ -0,0 +1,33 @@
+package cors
+
+import (
+	"net/http"
+
+	connectcors "connectrpc.com/cors"
+	"github.com/rs/cors"
+	"github.com/ruziba3vich/mm_article_service/internal/interceptors"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+)
+
+// Handler wraps next with CORS handling for browser clients, on top of the headers the
+// gRPC-Web and Connect protocols need it allows and exposes the service's own headers
+func Handler(cfg *config.CORSConfig, next http.Handler) http.Handler {
+	return cors.New(cors.Options{
+		AllowedOrigins: cfg.AllowedOrigins,
+		AllowedMethods: append(connectcors.AllowedMethods(), http.MethodPut, http.MethodDelete, http.MethodPatch),
+		AllowedHeaders: append(connectcors.AllowedHeaders(),
+			"Authorization",
+			interceptors.IdempotencyKeyHeader,
+			logging.RequestIDHeader,
+		),
+		ExposedHeaders: append(connectcors.ExposedHeaders(),
+			interceptors.IdempotentReplayHeader,
+			logging.RequestIDHeader,
+			"Retry-After",
+			"Content-Disposition",
+		),
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}).Handler(next)
+}
@@ -0,0 +1,196 @@
+// Package feeds publishes an author's or a tag's newest articles as RSS 2.0 and Atom feeds
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+
+	atomNamespace       = "http://www.w3.org/2005/Atom"
+	dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+	generator           = "mm_article_service"
+	uuidURNPrefix       = "urn:uuid:"
+)
+
+type (
+	// Feed is what both formats are rendered from, items are newest first
+	Feed struct {
+		Title       string
+		Description string
+		Link        string // the page the feed mirrors
+		Self        string // where the feed itself is served
+		Updated     time.Time
+		Items       []Item
+	}
+
+	// Item is one article, ID is the article ID and never changes, unlike its link
+	Item struct {
+		ID        string
+		Title     string
+		Link      string
+		Author    string
+		Excerpt   string
+		Published time.Time
+		Updated   time.Time
+		Tags      []string
+	}
+
+	rssDocument struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		AtomNS  string     `xml:"xmlns:atom,attr"`
+		DCNS    string     `xml:"xmlns:dc,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+
+	rssChannel struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		Generator     string    `xml:"generator"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		AtomLink      rssSelf   `xml:"atom:link"`
+		Items         []rssItem `xml:"item"`
+	}
+
+	rssSelf struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+
+	rssItem struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		GUID        rssGUID  `xml:"guid"`
+		PubDate     string   `xml:"pubDate"`
+		Creator     string   `xml:"dc:creator,omitempty"`
+		Description string   `xml:"description,omitempty"`
+		Categories  []string `xml:"category"`
+	}
+
+	rssGUID struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+
+	atomFeed struct {
+		XMLName   xml.Name    `xml:"feed"`
+		NS        string      `xml:"xmlns,attr"`
+		ID        string      `xml:"id"`
+		Title     string      `xml:"title"`
+		Subtitle  string      `xml:"subtitle,omitempty"`
+		Updated   string      `xml:"updated"`
+		Generator string      `xml:"generator"`
+		Links     []atomLink  `xml:"link"`
+		Entries   []atomEntry `xml:"entry"`
+	}
+
+	atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr,omitempty"`
+	}
+
+	atomEntry struct {
+		ID         string         `xml:"id"`
+		Title      string         `xml:"title"`
+		Link       atomLink       `xml:"link"`
+		Published  string         `xml:"published"`
+		Updated    string         `xml:"updated"`
+		Author     atomPerson     `xml:"author"`
+		Summary    string         `xml:"summary,omitempty"`
+		Categories []atomCategory `xml:"category"`
+	}
+
+	atomPerson struct {
+		Name string `xml:"name"`
+	}
+
+	atomCategory struct {
+		Term string `xml:"term,attr"`
+	}
+)
+
+// GUID identifies an article across renames, the slug in its link may change but its ID does not
+func GUID(articleID string) string {
+	return uuidURNPrefix + articleID
+}
+
+// RSS renders f as an RSS 2.0 channel. RSS wants an email in <author>, the name goes in <dc:creator>
+func RSS(f Feed) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  atomNamespace,
+		DCNS:    dublinCoreNamespace,
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			Generator:     generator,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			AtomLink:      rssSelf{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: GUID(item.ID)},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Description: item.Excerpt,
+			Categories:  item.Tags,
+		})
+	}
+	return marshal(doc)
+}
+
+// Atom renders f as an Atom 1.0 feed, identified by the URL it is served from
+func Atom(f Feed) ([]byte, error) {
+	doc := atomFeed{
+		NS:        atomNamespace,
+		ID:        f.Self,
+		Title:     f.Title,
+		Subtitle:  f.Description,
+		Updated:   f.Updated.UTC().Format(time.RFC3339),
+		Generator: generator,
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.Self, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        GUID(item.ID),
+			Title:     item.Title,
+			Link:      atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Author:    atomPerson{Name: item.Author},
+			Summary:   item.Excerpt,
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshal(doc)
+}
+
+func marshal(doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,231 @@
+package feeds
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var edited = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type fakeArticles struct {
+	repos.ArticleRepo
+	articles []*models.Article
+	queries  int
+}
+
+func (f *fakeArticles) FeedArticles(_ context.Context, userID, tag string, limit int) ([]*models.Article, error) {
+	f.queries++
+	var out []*models.Article
+	for _, a := range f.articles {
+		if (userID != "" && a.UserID == userID) || (tag != "" && contains(a.Tags, tag)) {
+			out = append(out, a)
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+type fakeUsers struct {
+	user_protos.UserServiceClient
+}
+
+func (fakeUsers) GetUserData(_ context.Context, in *user_protos.GetUserDataRequest, _ ...grpc.CallOption) (*user_protos.GetUserDataResponse, error) {
+	if in.UserId != "u-1" {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &user_protos.GetUserDataResponse{FullName: "Ali Valiyev", Username: "ali"}, nil
+}
+
+func contains(tags models.Tags, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func testFeed() Feed {
+	return Feed{
+		Title:   "Ali on Articles",
+		Link:    "https://example.com/users/u-1",
+		Self:    "https://api.example.com/feeds/authors/u-1/rss",
+		Updated: edited,
+		Items: []Item{{
+			ID:        "0190a1b2-0000-7000-8000-000000000001",
+			Title:     "Tom & Jerry <3",
+			Link:      "https://example.com/articles/tom-jerry",
+			Author:    "Ali Valiyev",
+			Excerpt:   "A short excerpt",
+			Published: edited.Add(-time.Hour),
+			Updated:   edited,
+			Tags:      []string{"go"},
+		}},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	out, err := RSS(testFeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Items []struct {
+			Title string `xml:"title"`
+			GUID  struct {
+				IsPermaLink string `xml:"isPermaLink,attr"`
+				Value       string `xml:",chardata"`
+			} `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+			Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if len(doc.Items) != 1 {
+		t.Fatalf("got %d items", len(doc.Items))
+	}
+	item := doc.Items[0]
+	if item.Title != "Tom & Jerry <3" || item.Creator != "Ali Valiyev" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item.GUID.Value != "urn:uuid:0190a1b2-0000-7000-8000-000000000001" || item.GUID.IsPermaLink != "false" {
+		t.Errorf("unexpected guid %+v", item.GUID)
+	}
+	if item.PubDate != "Fri, 01 Mar 2024 11:00:00 +0000" {
+		t.Errorf("unexpected pubDate %q", item.PubDate)
+	}
+}
+
+func TestAtom(t *testing.T) {
+	out, err := Atom(testFeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		ID      string `xml:"id"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID     string `xml:"id"`
+			Author string `xml:"author>name"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if doc.ID != "https://api.example.com/feeds/authors/u-1/rss" || doc.Updated != "2024-03-01T12:00:00Z" {
+		t.Errorf("unexpected feed %+v", doc)
+	}
+	if len(doc.Entries) != 1 || doc.Entries[0].ID != "urn:uuid:0190a1b2-0000-7000-8000-000000000001" || doc.Entries[0].Author != "Ali Valiyev" {
+		t.Errorf("unexpected entries %+v", doc.Entries)
+	}
+}
+
+func newTestHandler(t *testing.T, articles *fakeArticles) http.Handler {
+	t.Helper()
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Site:       &config.SiteConfig{Name: "Articles", BaseURL: "https://example.com"},
+		Feeds:      &config.FeedsConfig{Items: 20, CacheTTL: 60},
+		APIBaseURL: "https://api.example.com",
+	}
+	mux := http.NewServeMux()
+	NewHandler(cfg, articles, fakeUsers{}, nil, log).Register(mux)
+	return mux
+}
+
+func TestHandlerConditionalRequests(t *testing.T) {
+	articles := &fakeArticles{articles: []*models.Article{{
+		ID: "a-1", UserID: "u-1", Title: "Hello", Slug: "hello", CreatedAt: edited, EditedAt: edited, Tags: models.Tags{"go"},
+	}}}
+	h := newTestHandler(t, articles)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/authors/u-1/atom", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != AtomContentType {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "https://example.com/articles/hello") {
+		t.Errorf("article link missing:\n%s", rec.Body)
+	}
+	tag, lastModified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+	if tag == "" || lastModified != edited.Format(http.TimeFormat) {
+		t.Fatalf("missing validators, etag %q last-modified %q", tag, lastModified)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/authors/u-1/atom", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: got status %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/authors/u-1/atom", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: got status %d", rec.Code)
+	}
+}
+
+func TestHandlerNotFound(t *testing.T) {
+	h := newTestHandler(t, &fakeArticles{})
+	for _, target := range []string{"/feeds/authors/unknown/rss", "/feeds/authors/u-1/json", "/feeds/tags/%20/rss"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d", target, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/tags/Go/rss", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("empty tag feed: got status %d", rec.Code)
+	}
+}
+
+func TestHandlerSelfLinkIgnoresRequestHeaders(t *testing.T) {
+	h := newTestHandler(t, &fakeArticles{})
+	req := httptest.NewRequest(http.MethodGet, "/feeds/tags/Go/atom", nil)
+	req.Host = "attacker.example"
+	req.Header.Set("X-Forwarded-Proto", "http")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var doc struct {
+		ID string `xml:"id"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, rec.Body)
+	}
+	if doc.ID != "https://api.example.com/feeds/tags/go/atom" {
+		t.Errorf("feed id %q was not built from configuration", doc.ID)
+	}
+}
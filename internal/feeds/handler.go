@@ -0,0 +1,262 @@
+package feeds
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/internal/site"
+	"github.com/ruziba3vich/mm_article_service/internal/slug"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	formatRSS  = "rss"
+	formatAtom = "atom"
+
+	cacheKeyPrefix = "feed:"
+)
+
+// renderers maps the last path segment of a feed URL to its format
+var renderers = map[string]struct {
+	render      func(Feed) ([]byte, error)
+	contentType string
+}{
+	formatRSS:  {RSS, RSSContentType},
+	formatAtom: {Atom, AtomContentType},
+}
+
+// emptyFeedUpdated dates feeds without articles, a fixed value keeps their validators stable
+var emptyFeedUpdated = time.Unix(0, 0).UTC()
+
+type (
+	// Handler serves the feeds over HTTP. Rendered feeds are kept in Redis for the configured TTL,
+	// a Redis outage only costs a database query per request
+	Handler struct {
+		articles repos.ArticleRepo
+		users    user_protos.UserServiceClient
+		cache    *redis.Client
+		links    site.Links
+		items    int
+		ttl      time.Duration
+		logger   *logger.Logger
+	}
+
+	// cachedFeed is a rendered feed with the validators conditional requests are checked against
+	cachedFeed struct {
+		Body         []byte    `json:"body"`
+		ETag         string    `json:"etag"`
+		LastModified time.Time `json:"last_modified"`
+	}
+)
+
+func NewHandler(cfg *config.Config, articles repos.ArticleRepo, users user_protos.UserServiceClient, cache *redis.Client, logger *logger.Logger) *Handler {
+	return &Handler{
+		articles: articles,
+		users:    users,
+		cache:    cache,
+		links:    site.NewLinks(cfg),
+		items:    cfg.Feeds.Items,
+		ttl:      time.Duration(cfg.Feeds.CacheTTL) * time.Second,
+		logger:   logger,
+	}
+}
+
+// Register adds the feed routes to mux, GET patterns answer HEAD requests too
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /feeds/authors/{user_id}/{format}", h.authorFeed)
+	mux.HandleFunc("GET /feeds/tags/{tag}/{format}", h.tagFeed)
+}
+
+func (h *Handler) authorFeed(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	h.serve(w, r, "author:"+userID, func(ctx context.Context) (Feed, error) {
+		author, err := h.users.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: userID})
+		if err != nil {
+			return Feed{}, err
+		}
+		name := displayName(author)
+		articles, err := h.articles.FeedArticles(ctx, userID, "", h.items)
+		if err != nil {
+			return Feed{}, err
+		}
+		return h.feed(
+			fmt.Sprintf("%s on %s", name, h.links.Name),
+			fmt.Sprintf("The latest articles by %s", name),
+			h.links.Author(userID), h.links.AuthorFeed(userID, r.PathValue("format")), articles,
+			map[string]string{userID: name},
+		), nil
+	})
+}
+
+func (h *Handler) tagFeed(w http.ResponseWriter, r *http.Request) {
+	tag := slug.Tag(r.PathValue("tag"))
+	if tag == "" {
+		http.NotFound(w, r)
+		return
+	}
+	h.serve(w, r, "tag:"+tag, func(ctx context.Context) (Feed, error) {
+		articles, err := h.articles.FeedArticles(ctx, "", tag, h.items)
+		if err != nil {
+			return Feed{}, err
+		}
+		return h.feed(
+			fmt.Sprintf("#%s on %s", tag, h.links.Name),
+			fmt.Sprintf("The latest articles tagged %s", tag),
+			h.links.Tag(tag), h.links.TagFeed(tag, r.PathValue("format")), articles,
+			h.authorNames(ctx, articles),
+		), nil
+	})
+}
+
+// serve answers from the cache when it can, otherwise builds, renders and caches the feed.
+// http.ServeContent takes care of If-None-Match, If-Modified-Since and HEAD
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, key string, build func(context.Context) (Feed, error)) {
+	ctx := r.Context()
+	format := r.PathValue("format")
+	renderer, ok := renderers[format]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	cacheKey := cacheKeyPrefix + key + ":" + format
+	entry, ok := h.cached(ctx, cacheKey)
+	if !ok {
+		feed, err := build(ctx)
+		if err != nil {
+			h.writeError(w, r, key, err)
+			return
+		}
+		body, err := renderer.render(feed)
+		if err != nil {
+			h.writeError(w, r, key, err)
+			return
+		}
+		entry = cachedFeed{Body: body, ETag: etag(body), LastModified: feed.Updated}
+		h.store(ctx, cacheKey, entry)
+	}
+
+	w.Header().Set("Content-Type", renderer.contentType)
+	w.Header().Set("ETag", entry.ETag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.ttl.Seconds())))
+	http.ServeContent(w, r, "", entry.LastModified, bytes.NewReader(entry.Body))
+}
+
+// feed turns articles into a Feed, authors maps user IDs to the names shown on items
+func (h *Handler) feed(title, description, link, self string, articles []*models.Article, authors map[string]string) Feed {
+	f := Feed{
+		Title:       title,
+		Description: description,
+		Link:        link,
+		Self:        self,
+		Updated:     emptyFeedUpdated,
+	}
+	for _, article := range articles {
+		author := authors[article.UserID]
+		if author == "" {
+			author = h.links.Name
+		}
+		f.Items = append(f.Items, Item{
+			ID:        article.ID,
+			Title:     article.Title,
+			Link:      h.links.Article(article.Slug),
+			Author:    author,
+			Excerpt:   article.Excerpt,
+			Published: article.CreatedAt,
+			Updated:   article.EditedAt,
+			Tags:      article.Tags,
+		})
+		if article.EditedAt.After(f.Updated) {
+			f.Updated = article.EditedAt
+		}
+	}
+	return f
+}
+
+// authorNames looks up every distinct author once, a failed lookup leaves the name out rather
+// than failing the whole feed
+func (h *Handler) authorNames(ctx context.Context, articles []*models.Article) map[string]string {
+	names := make(map[string]string)
+	for _, article := range articles {
+		if _, ok := names[article.UserID]; ok {
+			continue
+		}
+		author, err := h.users.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: article.UserID})
+		if err != nil {
+			h.logger.Error("failed to fetch user data", logging.Fields(ctx, map[string]any{"user_id": article.UserID, "error": err.Error()}))
+			names[article.UserID] = ""
+			continue
+		}
+		names[article.UserID] = displayName(author)
+	}
+	return names
+}
+
+func (h *Handler) cached(ctx context.Context, key string) (cachedFeed, bool) {
+	var entry cachedFeed
+	if h.cache == nil {
+		return entry, false
+	}
+	data, err := h.cache.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return entry, false
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &entry)
+	}
+	if err != nil {
+		h.logger.Error("failed to read cached feed", logging.Fields(ctx, map[string]any{"key": key, "error": err.Error()}))
+		return entry, false
+	}
+	return entry, true
+}
+
+func (h *Handler) store(ctx context.Context, key string, entry cachedFeed) {
+	if h.cache == nil || h.ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = h.cache.Set(ctx, key, data, h.ttl).Err()
+	}
+	if err != nil {
+		h.logger.Error("failed to cache feed", logging.Fields(ctx, map[string]any{"key": key, "error": err.Error()}))
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, key string, err error) {
+	if status.Code(err) == codes.NotFound {
+		http.NotFound(w, r)
+		return
+	}
+	h.logger.Error("failed to build feed", logging.Fields(r.Context(), map[string]any{"feed": key, "error": err.Error()}))
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func displayName(user *user_protos.GetUserDataResponse) string {
+	if user.FullName != "" {
+		return user.FullName
+	}
+	return user.Username
+}
+
+// etag is a strong validator over the rendered bytes
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,81 @@
+// Package files serves article images through the HTTP gateway, so links embedded in article
+// bodies stay valid while the bucket itself remains private
+package files
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+// Route is the path prefix MINIO_PUBLIC_URL points at by default
+const Route = "/files/"
+
+// Handler streams stored images, only objects referenced by a picture of a published article are served
+type Handler struct {
+	files    repos.MinIOStorage
+	pictures repos.PictureRepo
+	logger   *logger.Logger
+}
+
+func NewHandler(files repos.MinIOStorage, pictures repos.PictureRepo, logger *logger.Logger) *Handler {
+	return &Handler{files: files, pictures: pictures, logger: logger}
+}
+
+// Register adds the file route to mux, GET patterns answer HEAD requests too
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+Route+"{name}", h.serve)
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	published, err := h.pictures.PublishedFiles(r.Context(), []string{name})
+	if err != nil {
+		h.writeError(w, r, name, err)
+		return
+	}
+	if !published[name] {
+		http.NotFound(w, r)
+		return
+	}
+
+	object, info, err := h.files.OpenFile(r.Context(), name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.writeError(w, r, name, err)
+		return
+	}
+	defer object.Close()
+
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	// object names are random and never reused, so the content behind a URL never changes
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// an uploaded SVG or HTML file must not run scripts under the API origin
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	if seeker, ok := object.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", info.LastModified, seeker)
+		return
+	}
+	w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	if r.Method != http.MethodHead {
+		_, _ = io.Copy(w, object)
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, name string, err error) {
+	h.logger.Error("failed to serve file", logging.Fields(r.Context(), map[string]any{"file_name": name, "error": err.Error()}))
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,84 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type seekCloser struct{ *bytes.Reader }
+
+func (seekCloser) Close() error { return nil }
+
+type fakeFiles struct {
+	repos.MinIOStorage
+	objects map[string][]byte
+}
+
+func (f fakeFiles) OpenFile(_ context.Context, name string) (io.ReadCloser, models.StoredFile, error) {
+	data, ok := f.objects[name]
+	if !ok {
+		return nil, models.StoredFile{}, fmt.Errorf("%w: %s", fs.ErrNotExist, name)
+	}
+	return seekCloser{bytes.NewReader(data)}, models.StoredFile{Name: name, Size: int64(len(data)), LastModified: time.Unix(1_700_000_000, 0)}, nil
+}
+
+type fakePictures struct {
+	repos.PictureRepo
+	published map[string]bool
+}
+
+func (f fakePictures) PublishedFiles(_ context.Context, names []string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	for _, name := range names {
+		out[name] = f.published[name]
+	}
+	return out, nil
+}
+
+func TestHandler(t *testing.T) {
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := fakeFiles{objects: map[string][]byte{"cover.png": []byte("png-bytes"), "orphan.png": []byte("x"), "draft.png": []byte("y")}}
+	pictures := fakePictures{published: map[string]bool{"cover.png": true, "lost.png": true}}
+	mux := http.NewServeMux()
+	NewHandler(files, pictures, log).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/cover.png", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "png-bytes" {
+		t.Fatalf("got status %d body %q", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/files/cover.png", nil)
+	req.Header.Set("If-Modified-Since", rec.Header().Get("Last-Modified"))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: got status %d", rec.Code)
+	}
+
+	for _, name := range []string{"orphan.png", "draft.png", "lost.png", "missing.png"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/"+name, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d", name, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type (
+	// errorBody is the JSON shape of every failed HTTP response, modelled on Google API errors
+	errorBody struct {
+		Error errorPayload `json:"error"`
+	}
+
+	errorPayload struct {
+		Code    int               `json:"code"`
+		Status  string            `json:"status"`
+		Message string            `json:"message"`
+		Details []json.RawMessage `json:"details,omitempty"`
+	}
+)
+
+// errorHandler writes gRPC errors as {"error": {...}} with the HTTP status mapped from the gRPC code
+func errorHandler(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+	httpStatus := runtime.HTTPStatusFromCode(st.Code())
+
+	body := errorBody{Error: errorPayload{
+		Code:    httpStatus,
+		Status:  code.Code(st.Code()).String(),
+		Message: st.Message(),
+	}}
+	for _, detail := range st.Proto().GetDetails() {
+		if raw, err := protojson.Marshal(detail); err == nil {
+			body.Error.Details = append(body.Error.Details, raw)
+		}
+	}
+
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			if name, ok := outgoingHeaderMatcher(key); ok {
+				for _, v := range values {
+					w.Header().Add(name, v)
+				}
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/cors"
+	"github.com/ruziba3vich/mm_article_service/internal/feeds"
+	"github.com/ruziba3vich/mm_article_service/internal/files"
+	"github.com/ruziba3vich/mm_article_service/internal/interceptors"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/sitemap"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// OpenAPIPath is where the generated OpenAPI document is served
+const OpenAPIPath = "/openapi.json"
+
+//go:embed openapi/article.swagger.json
+var openAPIDocument []byte
+
+// forwardedHeaders are passed between HTTP and gRPC metadata under their own names,
+// everything else uses the gateway defaults
+var forwardedHeaders = map[string]bool{
+	interceptors.IdempotencyKeyHeader:   true,
+	interceptors.IdempotentReplayHeader: true,
+	logging.RequestIDHeader:             true,
+	"retry-after":                       true,
+	"content-disposition":               true,
+}
+
+type (
+	// Server exposes ArticleService as REST/JSON, calls go through the gRPC listener
+	// so every interceptor applies to HTTP clients too
+	Server struct {
+		http *http.Server
+		conn *grpc.ClientConn
+		port string
+	}
+)
+
+func NewServer(cfg *config.Config, clientIP *interceptors.ClientIP, filesHandler *files.Handler, feedsHandler *feeds.Handler, sitemapHandler *sitemap.Handler) (*Server, error) {
+	conn, err := grpc.NewClient(net.JoinHostPort("localhost", cfg.GRPCPort),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(cfg.MaxMsgBytes), grpc.MaxCallRecvMsgSize(cfg.MaxMsgBytes)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial gRPC server for the gateway: %w", err)
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithErrorHandler(errorHandler),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithMetadata(clientIPMetadata(clientIP)),
+		// HTTPBodyMarshaler sends google.api.HttpBody responses such as exports as raw bytes
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.HTTPBodyMarshaler{Marshaler: &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}}),
+	)
+	if err := article_protos.RegisterArticleServiceHandler(context.Background(), mux, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	root := http.NewServeMux()
+	root.HandleFunc(OpenAPIPath, serveOpenAPI)
+	filesHandler.Register(root)
+	feedsHandler.Register(root)
+	sitemapHandler.Register(root)
+	root.Handle("/", multipartToJSON(mux, int64(cfg.MaxMsgBytes)))
+
+	return &Server{
+		http: &http.Server{Addr: fmt.Sprintf(":%s", cfg.HTTPPort), Handler: cors.Handler(cfg.CORS, root)},
+		conn: conn,
+		port: cfg.HTTPPort,
+	}, nil
+}
+
+// Start listens on the HTTP port and serves in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", s.port, err)
+	}
+	log.Printf("HTTP gateway listening on port %s", s.port)
+	go func() {
+		if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP gateway stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown drains in-flight HTTP requests and closes the loopback connection
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.http.Shutdown(ctx)
+	if cerr := s.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
+
+// clientIPMetadata tells the rate limiter which address a relayed request came from, the call
+// itself reaches the gRPC server from loopback
+func clientIPMetadata(clientIP *interceptors.ClientIP) func(context.Context, *http.Request) metadata.MD {
+	return func(_ context.Context, r *http.Request) metadata.MD {
+		host := clientIP.Resolve(r.RemoteAddr, r.Header.Values(clientIP.Header()))
+		return metadata.Pairs(interceptors.GatewayClientIPHeader, host)
+	}
+}
+
+func incomingHeaderMatcher(key string) (string, bool) {
+	lower := strings.ToLower(key)
+	// only the gateway itself may set the client address
+	if strings.TrimPrefix(lower, strings.ToLower(runtime.MetadataHeaderPrefix)) == interceptors.GatewayClientIPHeader {
+		return "", false
+	}
+	if forwardedHeaders[lower] {
+		return lower, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if forwardedHeaders[key] {
+		return key, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestErrorHandler(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "slow down").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(2 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	errorHandler(context.Background(), nil, nil, rec, httptest.NewRequest(http.MethodGet, "/", nil), st.Err())
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.Code != http.StatusTooManyRequests || body.Error.Status != "RESOURCE_EXHAUSTED" || body.Error.Message != "slow down" {
+		t.Fatalf("unexpected error body %+v", body.Error)
+	}
+	if len(body.Error.Details) != 1 || !bytes.Contains(body.Error.Details[0], []byte("RetryInfo")) {
+		t.Fatalf("details = %s, want RetryInfo", body.Error.Details)
+	}
+}
+
+func TestMultipartToJSON(t *testing.T) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	_ = form.WriteField("user_id", "u-1")
+	_ = form.WriteField("title", "Hello")
+	part, _ := form.CreateFormFile("files", "cover.png")
+	_, _ = part.Write([]byte("png-bytes"))
+	_ = form.Close()
+
+	var got map[string]any
+	handler := multipartToJSON(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		raw, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(raw, &got); err != nil {
+			t.Fatal(err)
+		}
+	}), 1<<20)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/articles", &buf)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got["user_id"] != "u-1" || got["title"] != "Hello" {
+		t.Fatalf("fields not converted: %v", got)
+	}
+	files, _ := got["files"].([]any)
+	if len(files) != 1 {
+		t.Fatalf("files = %v, want one entry", got["files"])
+	}
+	file := files[0].(map[string]any)
+	// []byte marshals as base64, which is what protojson expects for bytes fields
+	if file["name"] != "cover.png" || file["content"] != "cG5nLWJ5dGVz" {
+		t.Fatalf("unexpected file %v", file)
+	}
+}
+
+func TestIncomingHeaderMatcherDropsClientIP(t *testing.T) {
+	for _, key := range []string{"X-Gateway-Client-Ip", "Grpc-Metadata-X-Gateway-Client-Ip"} {
+		if name, ok := incomingHeaderMatcher(key); ok {
+			t.Errorf("%s was forwarded as %q", key, name)
+		}
+	}
+	if name, ok := incomingHeaderMatcher("Idempotency-Key"); !ok || name != "idempotency-key" {
+		t.Errorf("Idempotency-Key forwarded as %q, %v", name, ok)
+	}
+}
+
+func TestMultipartToJSONSizeLimit(t *testing.T) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, _ := form.CreateFormFile("files", "big.bin")
+	_, _ = part.Write(bytes.Repeat([]byte("x"), 4096))
+	_ = form.Close()
+
+	handler := multipartToJSON(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("an oversized form reached the gateway")
+	}), 1024)
+	req := httptest.NewRequest(http.MethodPost, "/v1/articles", &buf)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+)
+
+type uploadedFile struct {
+	Name    string `json:"name"`
+	Content []byte `json:"content"`
+}
+
+// multipartToJSON lets clients upload article files as multipart/form-data: form fields become
+// request fields and every file part is appended to "files", the result is handed to the
+// gateway as the equivalent JSON body. Forms are capped at maxBytes, the gRPC message limit, since
+// a larger upload could not be relayed anyway
+func multipartToJSON(next http.Handler, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		if err := r.ParseMultipartForm(maxBytes); err != nil {
+			writeBadRequest(w, "invalid multipart body: "+err.Error())
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		fields := make(map[string]any, len(r.MultipartForm.Value)+1)
+		for key, values := range r.MultipartForm.Value {
+			if len(values) > 0 {
+				fields[key] = values[0]
+			}
+		}
+		var files []uploadedFile
+		for _, headers := range r.MultipartForm.File {
+			for _, fh := range headers {
+				f, err := fh.Open()
+				if err != nil {
+					writeBadRequest(w, "failed to read uploaded file: "+err.Error())
+					return
+				}
+				content, err := io.ReadAll(f)
+				f.Close()
+				if err != nil {
+					writeBadRequest(w, "failed to read uploaded file: "+err.Error())
+					return
+				}
+				files = append(files, uploadedFile{Name: fh.Filename, Content: content})
+			}
+		}
+		if len(files) > 0 {
+			fields["files"] = files
+		}
+
+		body, err := json.Marshal(fields)
+		if err != nil {
+			writeBadRequest(w, err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeBadRequest(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(errorBody{Error: errorPayload{
+		Code:    http.StatusBadRequest,
+		Status:  code.Code_INVALID_ARGUMENT.String(),
+		Message: message,
+	}})
+}
@@ -0,0 +1,44 @@
+package idgen
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+)
+
+// uuidV7Generator issues RFC 9562 UUIDv7 ids: a millisecond timestamp followed by a
+// per-process monotonic sequence and random bits, so ids sort by time and do not collide across replicas
+type uuidV7Generator struct{}
+
+// NewUUIDv7 creates the production IDGenerator
+func NewUUIDv7() repos.IDGenerator {
+	return uuidV7Generator{}
+}
+
+func (uuidV7Generator) NewID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		// NewV7 only fails when the system random source does, which leaves nothing to recover
+		panic(fmt.Sprintf("idgen: failed to generate UUIDv7: %v", err))
+	}
+	return id.String()
+}
+
+// Sequential issues predictable, increasing UUID-shaped ids for tests
+type Sequential struct {
+	next atomic.Uint64
+}
+
+// NewSequential creates a Sequential generator whose first id ends in start
+func NewSequential(start uint64) *Sequential {
+	s := &Sequential{}
+	s.next.Store(start)
+	return s
+}
+
+func (s *Sequential) NewID() string {
+	n := s.next.Add(1) - 1
+	return fmt.Sprintf("00000000-0000-7000-8000-%012x", n)
+}
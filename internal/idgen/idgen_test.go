@@ -0,0 +1,60 @@
+package idgen
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUUIDv7UniqueAndSorted(t *testing.T) {
+	gen := NewUUIDv7()
+	const goroutines, perGoroutine = 8, 1000
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				id := gen.NewID()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate id %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	ids := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		id := gen.NewID()
+		parsed, err := uuid.Parse(id)
+		if err != nil || parsed.Version() != 7 {
+			t.Fatalf("NewID() = %q, want a UUIDv7", id)
+		}
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("sequentially generated ids are not sorted")
+	}
+}
+
+func TestSequential(t *testing.T) {
+	gen := NewSequential(1)
+	if got := gen.NewID(); got != "00000000-0000-7000-8000-000000000001" {
+		t.Errorf("first id = %q", got)
+	}
+	if got := gen.NewID(); got != "00000000-0000-7000-8000-000000000002" {
+		t.Errorf("second id = %q", got)
+	}
+	if _, err := uuid.Parse(gen.NewID()); err != nil {
+		t.Errorf("sequential id is not a valid uuid: %v", err)
+	}
+}
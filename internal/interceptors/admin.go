@@ -0,0 +1,50 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+	"path"
+
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminTokenHeader is the metadata key operators put the admin token in
+const AdminTokenHeader = "x-admin-token"
+
+// adminMethods are the maintenance RPCs only operators may call, the admin CLI runs the same
+// jobs without going through the API at all
+var adminMethods = map[string]bool{
+	"ReconcileLikes": true,
+}
+
+type (
+	// Admin guards the maintenance RPCs with a shared token, they are refused outright when no
+	// token is configured
+	Admin struct {
+		token []byte
+	}
+)
+
+func NewAdmin(cfg *config.Config) *Admin {
+	return &Admin{token: []byte(cfg.AdminToken)}
+}
+
+// Unary returns the server interceptor, it should run before anything that does work on the
+// request's behalf
+func (a *Admin) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !adminMethods[path.Base(info.FullMethod)] {
+			return handler(ctx, req)
+		}
+		if len(a.token) == 0 {
+			return nil, status.Error(codes.PermissionDenied, "admin RPCs are disabled, use the admin CLI")
+		}
+		if subtle.ConstantTimeCompare([]byte(incomingHeader(ctx, AdminTokenHeader)), a.token) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "a valid admin token is required")
+		}
+		return handler(ctx, req)
+	}
+}
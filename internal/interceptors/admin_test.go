@@ -0,0 +1,39 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminGuardsMaintenanceRPCs(t *testing.T) {
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	reconcile := &grpc.UnaryServerInfo{FullMethod: "/article_protos.ArticleService/ReconcileLikes"}
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(AdminTokenHeader, token))
+	}
+
+	for _, tc := range []struct {
+		name       string
+		configured string
+		ctx        context.Context
+		info       *grpc.UnaryServerInfo
+		want       codes.Code
+	}{
+		{"public method", "", context.Background(), &grpc.UnaryServerInfo{FullMethod: "/article_protos.ArticleService/GetArticles"}, codes.OK},
+		{"disabled", "", withToken(""), reconcile, codes.PermissionDenied},
+		{"missing token", "s3cret", context.Background(), reconcile, codes.PermissionDenied},
+		{"wrong token", "s3cret", withToken("guess"), reconcile, codes.PermissionDenied},
+		{"valid token", "s3cret", withToken("s3cret"), reconcile, codes.OK},
+	} {
+		_, err := NewAdmin(&config.Config{AdminToken: tc.configured}).Unary()(tc.ctx, nil, tc.info, handler)
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
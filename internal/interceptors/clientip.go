@@ -0,0 +1,84 @@
+package interceptors
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+)
+
+// ClientIP resolves the address a request really came from. A forwarded header is believed only
+// when the connection comes from a trusted proxy, and then only up to the first hop that is not
+// itself trusted, so a client cannot pick its own address by sending the header
+type ClientIP struct {
+	trusted []*net.IPNet
+	header  string
+}
+
+func NewClientIP(cfg *config.Config) (*ClientIP, error) {
+	c := &ClientIP{header: strings.ToLower(cfg.RateLimit.ClientIPHeader)}
+	for _, cidr := range cfg.RateLimit.TrustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		c.trusted = append(c.trusted, network)
+	}
+	return c, nil
+}
+
+// Header is the forwarded header the trusted proxies set, lower-cased
+func (c *ClientIP) Header() string {
+	return c.header
+}
+
+// Resolve returns the client address of a connection from remote carrying the given values of
+// the forwarded header. The right-most entry not added by a trusted proxy wins
+func (c *ClientIP) Resolve(remote string, forwarded []string) string {
+	host := hostOf(remote)
+	if !c.isTrusted(host) || c.header == "" {
+		return host
+	}
+	var hops []string
+	for _, value := range forwarded {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hostOf(hops[i])
+		if net.ParseIP(hop) == nil {
+			// a malformed entry may have been written by anyone, stop at the last known address
+			return host
+		}
+		host = hop
+		if !c.isTrusted(hop) {
+			return hop
+		}
+	}
+	return host
+}
+
+func (c *ClientIP) isTrusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range c.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOf strips the port from an address, bare IPs (v6 included) are returned as they are
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
@@ -0,0 +1,211 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// IdempotencyKeyHeader is the metadata key clients set to make retries safe
+	IdempotencyKeyHeader = "idempotency-key"
+	// IdempotentReplayHeader is set on responses replayed from a previous request
+	IdempotentReplayHeader = "idempotent-replayed"
+
+	maxIdempotencyKeyLength  = 255
+	idempotencyPurgeInterval = time.Hour
+)
+
+// idempotentMethods are the mutating RPCs that honour the idempotency-key header
+var idempotentMethods = map[string]bool{
+	article_protos.ArticleService_CreateArticle_FullMethodName:  true,
+	article_protos.ArticleService_UpdateArticle_FullMethodName:  true,
+	article_protos.ArticleService_RewriteArticle_FullMethodName: true,
+	article_protos.ArticleService_DeleteArticle_FullMethodName:  true,
+	article_protos.ArticleService_LikeArticle_FullMethodName:    true,
+	article_protos.ArticleService_UnlikeArticle_FullMethodName:  true,
+	article_protos.ArticleService_AddReaction_FullMethodName:    true,
+	article_protos.ArticleService_RemoveReaction_FullMethodName: true,
+	// a retried bundle import would otherwise create every draft twice
+	article_protos.ArticleService_ImportMarkdownBundle_FullMethodName: true,
+}
+
+type (
+	// Idempotency replays stored responses for retried requests carrying the same idempotency key
+	Idempotency struct {
+		store          repos.IdempotencyStore
+		logger         *logger.Logger
+		ttl            time.Duration
+		requestTimeout time.Duration
+		pendingTimeout time.Duration
+		cancel         context.CancelFunc
+		done           chan struct{}
+	}
+
+	// userScoped is implemented by every idempotent request, user_id is the acting user
+	userScoped interface {
+		GetUserId() string
+	}
+)
+
+func NewIdempotency(store repos.IdempotencyStore, logger *logger.Logger, cfg *config.Config) *Idempotency {
+	requestTimeout := time.Duration(cfg.Idempotency.RequestTimeout) * time.Second
+	pendingTimeout := time.Duration(cfg.Idempotency.PendingTimeout) * time.Second
+	if pendingTimeout <= requestTimeout {
+		// a key may only be taken over once the request holding it has certainly been cancelled
+		pendingTimeout = 2 * requestTimeout
+	}
+	return &Idempotency{
+		store:          store,
+		logger:         logger,
+		ttl:            time.Duration(cfg.Idempotency.TTL) * time.Second,
+		requestTimeout: requestTimeout,
+		pendingTimeout: pendingTimeout,
+	}
+}
+
+// Unary returns the server interceptor
+func (i *Idempotency) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !idempotentMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		key := incomingHeader(ctx, IdempotencyKeyHeader)
+		msg, ok := req.(proto.Message)
+		if key == "" || !ok {
+			return handler(ctx, req)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.Errorf(codes.InvalidArgument, "%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)
+		}
+
+		fingerprint, err := fingerprint(msg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fingerprint request: %v", err)
+		}
+		var caller string
+		if scoped, ok := req.(userScoped); ok {
+			caller = scoped.GetUserId()
+		}
+		record, reserved, err := i.store.Reserve(ctx, caller, key, info.FullMethod, fingerprint, i.ttl, i.pendingTimeout)
+		if err != nil {
+			i.logger.Error("failed to reserve idempotency key", logging.Fields(ctx, map[string]any{"method": info.FullMethod, "key": key, "error": err.Error()}))
+			return nil, status.Error(codes.Unavailable, "failed to reserve idempotency key")
+		}
+		if !reserved {
+			return i.replay(ctx, record, fingerprint)
+		}
+
+		handlerCtx, cancel := context.WithTimeout(ctx, i.requestTimeout)
+		resp, err := handler(handlerCtx, req)
+		cancel()
+		// the outcome is stored even when the client went away, that is exactly when it will retry
+		storeCtx := context.WithoutCancel(ctx)
+		if err != nil {
+			if rerr := i.store.Release(storeCtx, record); rerr != nil {
+				i.logger.Error("failed to release idempotency key", logging.Fields(ctx, map[string]any{"method": info.FullMethod, "key": key, "error": rerr.Error()}))
+			}
+			return nil, err
+		}
+		out, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+		data, err := proto.Marshal(out)
+		if err == nil {
+			err = i.store.Complete(storeCtx, record, string(out.ProtoReflect().Descriptor().FullName()), data)
+		}
+		if err != nil {
+			i.logger.Error("failed to store idempotent response", logging.Fields(ctx, map[string]any{"method": info.FullMethod, "key": key, "error": err.Error()}))
+		}
+		return resp, nil
+	}
+}
+
+func (i *Idempotency) replay(ctx context.Context, record *models.IdempotencyKey, fingerprint string) (any, error) {
+	if record.Fingerprint != fingerprint {
+		return nil, status.Errorf(codes.InvalidArgument, "%s was already used with a different request", IdempotencyKeyHeader)
+	}
+	if record.State != models.IdempotencyCompleted {
+		return nil, status.Error(codes.Aborted, "a request with this idempotency key is still in progress")
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(record.ResponseType))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unknown stored response type %q", record.ResponseType)
+	}
+	resp := mt.New().Interface()
+	if err := proto.Unmarshal(record.Response, resp); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode stored response: %v", err)
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayHeader, "true"))
+	return resp, nil
+}
+
+// Start launches the loop purging expired keys
+func (i *Idempotency) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+	i.done = make(chan struct{})
+
+	go func() {
+		defer close(i.done)
+		ticker := time.NewTicker(idempotencyPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := i.store.PurgeExpired(ctx); err != nil {
+					i.logger.Error("failed to purge expired idempotency keys", logging.Fields(ctx, map[string]any{"error": err.Error()}))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the purge loop
+func (i *Idempotency) Stop() {
+	if i.cancel == nil {
+		return
+	}
+	i.cancel()
+	<-i.done
+}
+
+// fingerprint hashes the deterministic wire encoding of the request
+func fingerprint(msg proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func incomingHeader(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotencyKey
+	tokens  int
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, caller, key, method, fingerprint string, ttl, _ time.Duration) (*models.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := caller + "/" + method + "/" + key
+	if existing, ok := s.records[id]; ok {
+		copied := *existing
+		return &copied, false, nil
+	}
+	s.tokens++
+	record := &models.IdempotencyKey{Caller: caller, Key: key, Method: method, Fingerprint: fingerprint, Token: fmt.Sprint(s.tokens), State: models.IdempotencyPending, ExpiresAt: time.Now().Add(ttl)}
+	s.records[id] = record
+	copied := *record
+	return &copied, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, claim *models.IdempotencyKey, responseType string, response []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := s.records[claim.Caller+"/"+claim.Method+"/"+claim.Key]
+	if record == nil || record.Token != claim.Token {
+		return errors.New("claim lost")
+	}
+	record.State, record.ResponseType, record.Response = models.IdempotencyCompleted, responseType, response
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, claim *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := claim.Caller + "/" + claim.Method + "/" + claim.Key
+	if record := s.records[id]; record != nil && record.Token == claim.Token {
+		delete(s.records, id)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) PurgeExpired(context.Context) (int64, error) { return 0, nil }
+
+func newTestIdempotency(t *testing.T) *Idempotency {
+	t.Helper()
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Idempotency: &config.IdempotencyConfig{TTL: 60, RequestTimeout: 60, PendingTimeout: 120}}
+	return NewIdempotency(&memoryIdempotencyStore{records: map[string]*models.IdempotencyKey{}}, log, cfg)
+}
+
+func TestIdempotencyReplaysAndRejectsReuse(t *testing.T) {
+	interceptor := newTestIdempotency(t).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: article_protos.ArticleService_CreateArticle_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "retry-1"))
+
+	calls := 0
+	handler := func(context.Context, any) (any, error) {
+		calls++
+		return &article_protos.ArticleEntity{Id: "created-once"}, nil
+	}
+	req := &article_protos.CreateArticleRequest{UserId: "u", Title: "t", Content: "c"}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		resp, err := interceptor(ctx, req, info, handler)
+		if err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+		if got := resp.(*article_protos.ArticleEntity).Id; got != "created-once" {
+			t.Fatalf("attempt %d: id = %q", attempt, got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	other := proto.Clone(req).(*article_protos.CreateArticleRequest)
+	other.Title = "different"
+	if _, err := interceptor(ctx, other, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("reuse with different payload: got %v, want InvalidArgument", err)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnError(t *testing.T) {
+	interceptor := newTestIdempotency(t).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: article_protos.ArticleService_LikeArticle_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "retry-2"))
+	req := &article_protos.LikeArticleRequest{UserId: "u", ArticleId: "a"}
+
+	failing := func(context.Context, any) (any, error) { return nil, errors.New("boom") }
+	if _, err := interceptor(ctx, req, info, failing); err == nil {
+		t.Fatal("expected handler error")
+	}
+
+	succeeding := func(context.Context, any) (any, error) { return &article_protos.LikeArticleResponse{}, nil }
+	if _, err := interceptor(ctx, req, info, succeeding); err != nil {
+		t.Errorf("retry after failure: %v", err)
+	}
+}
+
+func TestIdempotencyKeysAreScopedToTheCaller(t *testing.T) {
+	interceptor := newTestIdempotency(t).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: article_protos.ArticleService_LikeArticle_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "shared"))
+
+	calls := 0
+	handler := func(context.Context, any) (any, error) {
+		calls++
+		return &article_protos.LikeArticleResponse{Success: true}, nil
+	}
+	for _, user := range []string{"u-1", "u-2"} {
+		if _, err := interceptor(ctx, &article_protos.LikeArticleRequest{UserId: user, ArticleId: "a"}, info, handler); err != nil {
+			t.Fatalf("%s: %v", user, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want once per caller", calls)
+	}
+}
+
+func TestIdempotencyBoundsTheRequest(t *testing.T) {
+	idem := newTestIdempotency(t)
+	if idem.pendingTimeout <= idem.requestTimeout {
+		t.Fatalf("pending timeout %s must outlast request timeout %s", idem.pendingTimeout, idem.requestTimeout)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: article_protos.ArticleService_LikeArticle_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "bounded"))
+
+	handler := func(ctx context.Context, _ any) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > idem.requestTimeout {
+			t.Errorf("handler deadline %v (set %t), want within %s", deadline, ok, idem.requestTimeout)
+		}
+		return &article_protos.LikeArticleResponse{}, nil
+	}
+	if _, err := idem.Unary()(ctx, &article_protos.LikeArticleRequest{UserId: "u", ArticleId: "a"}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const maxRequestIDLength = 128
+
+type (
+	// Logging logs every RPC once and threads the x-request-id correlation id through the context
+	Logging struct {
+		logger *logger.Logger
+	}
+)
+
+func NewLogging(logger *logger.Logger) *Logging {
+	return &Logging{logger: logger}
+}
+
+// Unary returns the server interceptor, it should run first so later interceptors see the request id
+func (l *Logging) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		requestID := incomingHeader(ctx, logging.RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		ctx = logging.WithRequestID(ctx, requestID)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(logging.RequestIDHeader, requestID))
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := logging.Fields(ctx, map[string]any{
+			"method":      info.FullMethod,
+			"caller":      caller(ctx),
+			"duration_ms": time.Since(start).Milliseconds(),
+			"code":        code.String(),
+		})
+		if err != nil {
+			fields["error"] = status.Convert(err).Message()
+		}
+		switch code {
+		case codes.OK:
+			l.logger.Info("rpc finished", fields)
+		case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+			l.logger.Error("rpc failed", fields)
+		default:
+			l.logger.Warn("rpc rejected", fields)
+		}
+		return resp, err
+	}
+}
+
+// UnaryClient forwards the request id of the current request to downstream services
+func (l *Logging) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID := logging.RequestID(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, logging.RequestIDHeader, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// caller describes who made the request, the peer address and user agent when available
+func caller(ctx context.Context) string {
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	if ua := incomingHeader(ctx, "user-agent"); ua != "" {
+		return addr + " (" + ua + ")"
+	}
+	return addr
+}
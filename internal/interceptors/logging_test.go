@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestLogging(t *testing.T) *Logging {
+	t.Helper()
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewLogging(log)
+}
+
+func TestLoggingPropagatesRequestID(t *testing.T) {
+	l := newTestLogging(t)
+	info := &grpc.UnaryServerInfo{FullMethod: "/article.ArticleService/GetArticles"}
+
+	var forwarded []string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		forwarded = md.Get(logging.RequestIDHeader)
+		return nil
+	}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return nil, l.UnaryClient()(ctx, "/user.UserService/GetUserData", nil, nil, nil, invoker)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(logging.RequestIDHeader, "req-42"))
+	if _, err := l.Unary()(ctx, nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if len(forwarded) != 1 || forwarded[0] != "req-42" {
+		t.Errorf("forwarded request id = %v, want [req-42]", forwarded)
+	}
+
+	if _, err := l.Unary()(context.Background(), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if len(forwarded) != 1 || forwarded[0] == "" {
+		t.Errorf("generated request id was not forwarded: %v", forwarded)
+	}
+}
@@ -0,0 +1,144 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/ratelimit"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// GatewayClientIPHeader carries the client address the HTTP gateway resolved for a request. The
+	// gateway sets it itself and drops any value a client sent, so it is only trusted on calls
+	// from loopback, where the in-process gateway relays from
+	GatewayClientIPHeader = "x-gateway-client-ip"
+
+	rateLimitKeyPrefix = "ratelimit"
+	// fallbackLogInterval throttles the log line written while Redis is unreachable
+	fallbackLogInterval = time.Minute
+)
+
+type (
+	// RateLimit enforces token-bucket limits per method and caller
+	RateLimit struct {
+		limiter      ratelimit.Limiter
+		logger       *logger.Logger
+		enabled      bool
+		defaultLimit ratelimit.Limit
+		methodLimits map[string]ratelimit.Limit
+		clientIP     *ClientIP
+		lastFallback atomic.Int64
+	}
+)
+
+func NewRateLimit(client *redis.Client, logger *logger.Logger, cfg *config.Config, clientIP *ClientIP) (*RateLimit, error) {
+	defaultLimit, err := ratelimit.ParseLimit(cfg.RateLimit.Default)
+	if err != nil {
+		return nil, err
+	}
+	methodLimits, err := ratelimit.ParseMethodLimits(cfg.RateLimit.Methods)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RateLimit{
+		logger:       logger,
+		enabled:      cfg.RateLimit.Enabled,
+		defaultLimit: defaultLimit,
+		methodLimits: methodLimits,
+		clientIP:     clientIP,
+	}
+	r.limiter = ratelimit.WithFallback(
+		ratelimit.NewRedisLimiter(client),
+		ratelimit.NewMemoryLimiter(),
+		time.Duration(cfg.RateLimit.RedisTimeout)*time.Millisecond,
+		time.Duration(cfg.RateLimit.FallbackCooldown)*time.Second,
+		r.logFallback,
+	)
+	return r, nil
+}
+
+// Unary returns the server interceptor
+func (r *RateLimit) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !r.enabled {
+			return handler(ctx, req)
+		}
+		method := path.Base(info.FullMethod)
+		limit, ok := r.methodLimits[method]
+		if !ok {
+			limit = r.defaultLimit
+		}
+
+		key := rateLimitKeyPrefix + ":" + method + ":" + r.callerKey(ctx)
+		allowed, retryAfter, err := r.limiter.Allow(ctx, key, limit)
+		if err != nil {
+			// both limiters failed, prefer serving the request over rejecting it
+			r.logger.Error("failed to check rate limit", logging.Fields(ctx, map[string]any{"method": method, "error": err.Error()}))
+			return handler(ctx, req)
+		}
+		if !allowed {
+			return nil, rateLimitedError(ctx, method, retryAfter)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (r *RateLimit) logFallback(err error) {
+	now := time.Now().UnixNano()
+	last := r.lastFallback.Load()
+	if now-last < int64(fallbackLogInterval) || !r.lastFallback.CompareAndSwap(last, now) {
+		return
+	}
+	r.logger.Warn("redis rate limiter unavailable, using in-memory limits", map[string]any{"error": err.Error()})
+}
+
+// callerKey identifies the caller by its address. The service has no authentication of its own and
+// the user_id in a request is whatever the caller put there, keying on it would let one client
+// spread its calls over any number of buckets. Behind a proxy the address is only as good as
+// RATE_LIMIT_TRUSTED_PROXIES: a proxy missing from it makes all of its clients share one bucket
+func (r *RateLimit) callerKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	remote := p.Addr.String()
+	// calls relayed by the HTTP gateway arrive from loopback, the gateway already resolved the client
+	if ip := net.ParseIP(hostOf(remote)); ip != nil && ip.IsLoopback() {
+		if client := incomingHeader(ctx, GatewayClientIPHeader); client != "" {
+			return "ip:" + client
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return "ip:" + r.clientIP.Resolve(remote, md.Get(r.clientIP.Header()))
+}
+
+func rateLimitedError(ctx context.Context, method string, retryAfter time.Duration) error {
+	seconds := int64(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.FormatInt(seconds, 10)))
+
+	st := status.Newf(codes.ResourceExhausted, "rate limit exceeded for %s, retry in %s", method, retryAfter.Round(time.Millisecond))
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,88 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/ratelimit"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(addr string) context.Context {
+	tcp, _ := net.ResolveTCPAddr("tcp", addr)
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: tcp})
+}
+
+func newTestClientIP(t *testing.T, trusted ...string) *ClientIP {
+	t.Helper()
+	clientIP, err := NewClientIP(&config.Config{RateLimit: &config.RateLimitConfig{
+		TrustedProxies: append([]string{"127.0.0.0/8", "::1/128"}, trusted...),
+		ClientIPHeader: "X-Forwarded-For",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientIP
+}
+
+func TestRateLimitIgnoresRequestUserID(t *testing.T) {
+	r := &RateLimit{
+		limiter:      ratelimit.NewMemoryLimiter(),
+		enabled:      true,
+		defaultLimit: ratelimit.Limit{Rate: 0.001, Burst: 1},
+		clientIP:     newTestClientIP(t),
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/article.ArticleService/LikeArticle"}
+	handler := func(context.Context, any) (any, error) { return &article_protos.LikeArticleResponse{}, nil }
+	ctx := peerContext("203.0.113.7:4000")
+
+	if _, err := r.Unary()(ctx, &article_protos.LikeArticleRequest{UserId: "u-1"}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.Unary()(ctx, &article_protos.LikeArticleRequest{UserId: "u-2"}, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("a new user_id from the same peer got a fresh bucket, err %v", err)
+	}
+}
+
+func TestCallerKeyTrustsGatewayOnlyFromLoopback(t *testing.T) {
+	r := &RateLimit{clientIP: newTestClientIP(t)}
+	md := metadata.Pairs(GatewayClientIPHeader, "198.51.100.4", "x-forwarded-for", "10.0.0.1")
+	if got := r.callerKey(metadata.NewIncomingContext(peerContext("127.0.0.1:5000"), md)); got != "ip:198.51.100.4" {
+		t.Errorf("relayed by the gateway: got %q", got)
+	}
+	if got := r.callerKey(metadata.NewIncomingContext(peerContext("203.0.113.7:4000"), md)); got != "ip:203.0.113.7" {
+		t.Errorf("direct caller: got %q", got)
+	}
+}
+
+func TestCallerKeyBehindTrustedProxy(t *testing.T) {
+	r := &RateLimit{clientIP: newTestClientIP(t, "10.0.0.0/8")}
+	for _, tc := range []struct {
+		name, peer, forwarded, want string
+	}{
+		{"client behind the balancer", "10.1.2.3:443", "198.51.100.9", "ip:198.51.100.9"},
+		{"through two trusted hops", "10.1.2.3:443", "198.51.100.9, 10.4.5.6", "ip:198.51.100.9"},
+		{"spoofed entries are left of the real client", "10.1.2.3:443", "192.0.2.1, 198.51.100.9", "ip:198.51.100.9"},
+		{"garbage stops the walk", "10.1.2.3:443", "not-an-ip", "ip:10.1.2.3"},
+		{"untrusted peer", "203.0.113.7:4000", "198.51.100.9", "ip:203.0.113.7"},
+	} {
+		ctx := metadata.NewIncomingContext(peerContext(tc.peer), metadata.Pairs("x-forwarded-for", tc.forwarded))
+		if got := r.callerKey(ctx); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	// the gateway resolves HTTP clients the same way before relaying them from loopback
+	header := http.Header{"X-Forwarded-For": []string{"198.51.100.9, 10.4.5.6"}}
+	if got := r.clientIP.Resolve("10.1.2.3:443", header.Values(r.clientIP.Header())); got != "198.51.100.9" {
+		t.Errorf("gateway resolve: got %q", got)
+	}
+}
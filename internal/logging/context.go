@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the metadata key carrying the correlation id between services
+const RequestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// WithRequestID stores the correlation id of the current request in ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the correlation id stored in ctx, if any
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Fields adds the request id and trace id from ctx to fields, so every log entry of a request can be correlated
+func Fields(ctx context.Context, fields map[string]any) map[string]any {
+	if fields == nil {
+		fields = make(map[string]any, 2)
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields["trace_id"] = sc.TraceID().String()
+	}
+	return fields
+}
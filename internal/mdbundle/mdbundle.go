@@ -0,0 +1,374 @@
+// Package mdbundle reads zip archives of Markdown files as exported by static site generators and
+// other blogging platforms: YAML front matter on top of every file and images referenced by relative path
+package mdbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// limits guarding against archives that inflate far beyond their upload size
+const (
+	maxEntries    = 2_000
+	maxTotalBytes = 256 << 20
+	maxFileBytes  = 32 << 20
+)
+
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true,
+}
+
+// dateLayouts are tried in order for front matter dates written as strings
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var (
+	// inlineImage matches ![alt](destination "title"), group 1 is the destination
+	inlineImage = regexp.MustCompile(`!\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]+)(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+	// referenceDefinition matches [label]: destination, group 1 is the destination
+	referenceDefinition = regexp.MustCompile(`(?m)^ {0,3}\[[^\]]+\]:[ \t]*(<[^>]*>|\S+)`)
+	// htmlImage matches <img src="...">, group 1 or 2 is the destination
+	htmlImage = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+type (
+	// Bundle is an opened archive, its documents are sorted by path
+	Bundle struct {
+		files     map[string]*zip.File
+		Documents []*Document
+	}
+
+	// Document is one Markdown file, Err is set when it cannot become an article
+	Document struct {
+		Path     string
+		Title    string
+		Date     time.Time // zero when the front matter has none
+		Tags     []string
+		Body     string // the Markdown without its front matter
+		Warnings []string
+		Err      error
+	}
+
+	// ImageRef is a local image a document points at
+	ImageRef struct {
+		Ref  string // the destination as written in the document
+		Path string // the file inside the bundle
+	}
+
+	frontMatter struct {
+		Title string `yaml:"title"`
+		Date  any    `yaml:"date"`
+		Tags  any    `yaml:"tags"`
+	}
+
+	// span is the byte range of a link destination in a document body
+	span struct {
+		start, end int
+	}
+)
+
+// Open reads the archive held in data and parses every Markdown file in it
+func Open(data []byte) (*Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a zip archive: %w", err)
+	}
+	if len(zr.File) > maxEntries {
+		return nil, fmt.Errorf("archive has %d entries, at most %d are allowed", len(zr.File), maxEntries)
+	}
+
+	b := &Bundle{files: make(map[string]*zip.File, len(zr.File))}
+	var total uint64
+	for _, f := range zr.File {
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		if f.FileInfo().IsDir() || hidden(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "../") {
+			continue
+		}
+		if total += f.UncompressedSize64; total > maxTotalBytes {
+			return nil, fmt.Errorf("archive inflates to more than %d bytes", maxTotalBytes)
+		}
+		b.files[name] = f
+	}
+
+	for name := range b.files {
+		ext := strings.ToLower(path.Ext(name))
+		if ext != ".md" && ext != ".markdown" {
+			continue
+		}
+		doc := &Document{Path: name}
+		if source, err := b.ReadFile(name); err != nil {
+			doc.Err = err
+		} else {
+			doc.parse(string(source))
+		}
+		b.Documents = append(b.Documents, doc)
+	}
+	if len(b.Documents) == 0 {
+		return nil, errors.New("archive holds no Markdown files")
+	}
+	sort.Slice(b.Documents, func(i, j int) bool { return b.Documents[i].Path < b.Documents[j].Path })
+	return b, nil
+}
+
+// ReadFile returns the contents of a file in the bundle
+func (b *Bundle) ReadFile(name string) ([]byte, error) {
+	f, ok := b.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: file not found in the bundle", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(data) > maxFileBytes {
+		return nil, fmt.Errorf("%s: larger than %d bytes", name, maxFileBytes)
+	}
+	return data, nil
+}
+
+// Images resolves the local images doc references, remote and data URLs are left alone.
+// Images that are not in the bundle are reported as warnings and keep their original link
+func (b *Bundle) Images(doc *Document) ([]ImageRef, []string) {
+	var refs []ImageRef
+	var warnings []string
+	seen := map[string]bool{}
+	for _, d := range destinations(doc.Body) {
+		ref := doc.Body[d.start:d.end]
+		if seen[ref] || !local(ref) {
+			continue
+		}
+		seen[ref] = true
+
+		name := resolve(doc.Path, ref)
+		if !imageExtensions[strings.ToLower(path.Ext(name))] {
+			continue
+		}
+		if _, ok := b.files[name]; !ok {
+			warnings = append(warnings, fmt.Sprintf("image %q not found in the bundle", ref))
+			continue
+		}
+		refs = append(refs, ImageRef{Ref: ref, Path: name})
+	}
+	return refs, warnings
+}
+
+// RewriteImages replaces every link destination in body for which replace returns true
+func RewriteImages(body string, replace func(ref string) (string, bool)) string {
+	var sb strings.Builder
+	last := 0
+	for _, d := range destinations(body) {
+		target, ok := replace(body[d.start:d.end])
+		if !ok {
+			continue
+		}
+		sb.WriteString(body[last:d.start])
+		sb.WriteString(target)
+		last = d.end
+	}
+	sb.WriteString(body[last:])
+	return sb.String()
+}
+
+// parse splits off the front matter and fills in the title, date and tags
+func (d *Document) parse(source string) {
+	source = strings.TrimPrefix(source, "\ufeff")
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var fm frontMatter
+	if raw, body, ok := cutFrontMatter(source, "---"); ok {
+		if err := yaml.Unmarshal([]byte(raw), &fm); err != nil {
+			d.Warnings = append(d.Warnings, fmt.Sprintf("front matter ignored: %v", err))
+		}
+		source = body
+	} else if _, body, ok := cutFrontMatter(source, "+++"); ok {
+		d.Warnings = append(d.Warnings, "TOML front matter is not supported and was ignored")
+		source = body
+	}
+
+	d.Title = strings.TrimSpace(fm.Title)
+	if d.Title == "" {
+		d.Title, source = cutHeading(source)
+	}
+	if d.Title == "" {
+		d.Title = strings.TrimSuffix(path.Base(d.Path), path.Ext(d.Path))
+	}
+	d.Body = strings.TrimSpace(source)
+	if d.Body == "" {
+		d.Err = errors.New("document has no content")
+	}
+
+	switch v := fm.Date.(type) {
+	case nil:
+	case time.Time:
+		d.Date = v
+	case string:
+		if d.Date = parseDate(v); d.Date.IsZero() {
+			d.Warnings = append(d.Warnings, fmt.Sprintf("date %q not understood, the import time is used", v))
+		}
+	default:
+		d.Warnings = append(d.Warnings, fmt.Sprintf("date %v not understood, the import time is used", v))
+	}
+
+	switch v := fm.Tags.(type) {
+	case string:
+		d.Tags = strings.Split(v, ",")
+	case []any:
+		for _, tag := range v {
+			d.Tags = append(d.Tags, fmt.Sprint(tag))
+		}
+	}
+}
+
+// cutFrontMatter splits source at a front matter block fenced by delim lines
+func cutFrontMatter(source, delim string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(source, delim+"\n")
+	if !ok {
+		return "", source, false
+	}
+	if body, ok := strings.CutPrefix(rest, delim+"\n"); ok {
+		return "", body, true
+	}
+	if raw, body, ok := strings.Cut(rest, "\n"+delim+"\n"); ok {
+		return raw, body, true
+	}
+	if raw, ok := strings.CutSuffix(rest, "\n"+delim); ok {
+		return raw, "", true
+	}
+	return "", source, false
+}
+
+// cutHeading takes the title from a leading "# " heading, which is then dropped from the body
+func cutHeading(source string) (string, string) {
+	trimmed := strings.TrimLeft(source, "\n")
+	line, rest, _ := strings.Cut(trimmed, "\n")
+	if title, ok := strings.CutPrefix(line, "# "); ok {
+		return strings.TrimSpace(strings.TrimRight(title, "#")), rest
+	}
+	return "", source
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// destinations finds the link destinations of images and reference definitions outside code blocks, in order
+func destinations(body string) []span {
+	code := fencedBlocks(body)
+	inCode := func(i int) bool {
+		for _, c := range code {
+			if i >= c.start && i < c.end {
+				return true
+			}
+		}
+		return false
+	}
+
+	var spans []span
+	add := func(m []int, groups ...int) {
+		for _, g := range groups {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 || inCode(start) {
+				continue
+			}
+			// <destination> keeps its brackets, only the inside is the reference
+			if end-start >= 2 && body[start] == '<' && body[end-1] == '>' {
+				start, end = start+1, end-1
+			}
+			spans = append(spans, span{start, end})
+		}
+	}
+	for _, m := range inlineImage.FindAllStringSubmatchIndex(body, -1) {
+		add(m, 1)
+	}
+	for _, m := range referenceDefinition.FindAllStringSubmatchIndex(body, -1) {
+		add(m, 1)
+	}
+	for _, m := range htmlImage.FindAllStringSubmatchIndex(body, -1) {
+		add(m, 1, 2)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// fencedBlocks returns the byte ranges of ``` and ~~~ code blocks, an unclosed block runs to the end
+func fencedBlocks(body string) []span {
+	var blocks []span
+	fence, start := "", 0
+	for offset := 0; offset < len(body); {
+		line, _, _ := strings.Cut(body[offset:], "\n")
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			fence, start = trimmed[:3], offset
+		case fence != "" && strings.HasPrefix(trimmed, fence):
+			blocks = append(blocks, span{start, offset + len(line)})
+			fence = ""
+		}
+		offset += len(line) + 1
+	}
+	if fence != "" {
+		blocks = append(blocks, span{start, len(body)})
+	}
+	return blocks
+}
+
+// local reports whether ref points into the bundle rather than at a URL
+func local(ref string) bool {
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "//") {
+		return false
+	}
+	u, err := url.Parse(ref)
+	return err == nil && u.Scheme == ""
+}
+
+// resolve turns ref into a bundle path, relative to the document or, with a leading slash, to the root
+func resolve(docPath, ref string) string {
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		ref = unescaped
+	}
+	if strings.HasPrefix(ref, "/") {
+		return path.Clean(strings.TrimPrefix(ref, "/"))
+	}
+	return path.Join(path.Dir(docPath), ref)
+}
+
+// hidden skips editor and OS metadata such as .DS_Store and __MACOSX
+func hidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package mdbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenParsesFrontMatter(t *testing.T) {
+	b, err := Open(zipOf(t, map[string]string{
+		"blog/first.md":            "---\ntitle: First post\ndate: 2021-03-04\ntags: [Go, Machine Learning]\n---\nHello\r\n",
+		"blog/second.markdown":     "---\ndate: \"2022-01-02 15:04\"\ntags: go, web\n---\n\n# Heading title\n\nBody",
+		"blog/untitled.md":         "just text",
+		"blog/empty.md":            "---\ntitle: Empty\n---\n",
+		"blog/toml.md":             "+++\ntitle = \"x\"\n+++\nbody",
+		"__MACOSX/blog/._first.md": "junk",
+		"notes.txt":                "not markdown",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	docs := map[string]*Document{}
+	for _, d := range b.Documents {
+		paths = append(paths, d.Path)
+		docs[d.Path] = d
+	}
+	if got := strings.Join(paths, ","); got != "blog/empty.md,blog/first.md,blog/second.markdown,blog/toml.md,blog/untitled.md" {
+		t.Fatalf("documents = %s", got)
+	}
+
+	first := docs["blog/first.md"]
+	if first.Title != "First post" || first.Body != "Hello" || !first.Date.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("first = %+v", first)
+	}
+	if strings.Join(first.Tags, "|") != "Go|Machine Learning" {
+		t.Errorf("first tags = %q", first.Tags)
+	}
+
+	second := docs["blog/second.markdown"]
+	if second.Title != "Heading title" || second.Body != "Body" || second.Date.Hour() != 15 || len(second.Tags) != 2 {
+		t.Errorf("second = %+v", second)
+	}
+	if docs["blog/untitled.md"].Title != "untitled" {
+		t.Errorf("untitled title = %q", docs["blog/untitled.md"].Title)
+	}
+	if docs["blog/empty.md"].Err == nil {
+		t.Error("empty document was accepted")
+	}
+	if toml := docs["blog/toml.md"]; toml.Body != "body" || len(toml.Warnings) != 1 {
+		t.Errorf("toml = %+v", toml)
+	}
+}
+
+func TestOpenRejectsArchivesWithoutMarkdown(t *testing.T) {
+	if _, err := Open([]byte("not a zip")); err == nil {
+		t.Error("garbage was accepted")
+	}
+	if _, err := Open(zipOf(t, map[string]string{"a.txt": "x"})); err == nil {
+		t.Error("archive without Markdown was accepted")
+	}
+}
+
+func TestImagesAndRewrite(t *testing.T) {
+	body := strings.Join([]string{
+		`![cover](images/cover.png "Cover")`,
+		`![same](images/cover.png)`,
+		`![spaced](<../shared/my%20pic.jpg>)`,
+		`![remote](https://example.com/a.png)`,
+		`![missing](gone.png)`,
+		`<img alt="x" src='/root.gif'>`,
+		`[ref]: ./images/ref.webp`,
+		`[doc]: ./other.md`,
+		"```",
+		`![in code](images/cover.png)`,
+		"```",
+	}, "\n")
+	b, err := Open(zipOf(t, map[string]string{
+		"posts/a.md":             body,
+		"posts/images/cover.png": "png",
+		"posts/images/ref.webp":  "webp",
+		"shared/my pic.jpg":      "jpg",
+		"root.gif":               "gif",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := b.Documents[0]
+
+	refs, warnings := b.Images(doc)
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.Ref+"="+r.Path)
+	}
+	want := "images/cover.png=posts/images/cover.png,../shared/my%20pic.jpg=shared/my pic.jpg,/root.gif=root.gif,./images/ref.webp=posts/images/ref.webp"
+	if strings.Join(got, ",") != want {
+		t.Errorf("images = %s", strings.Join(got, ","))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "gone.png") {
+		t.Errorf("warnings = %q", warnings)
+	}
+	if data, err := b.ReadFile("shared/my pic.jpg"); err != nil || string(data) != "jpg" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+
+	urls := map[string]string{}
+	for _, r := range refs {
+		urls[r.Ref] = "https://cdn/" + r.Path
+	}
+	rewritten := RewriteImages(doc.Body, func(ref string) (string, bool) {
+		u, ok := urls[ref]
+		return u, ok
+	})
+	for _, line := range []string{
+		`![cover](https://cdn/posts/images/cover.png "Cover")`,
+		`![same](https://cdn/posts/images/cover.png)`,
+		`![spaced](<https://cdn/shared/my pic.jpg>)`,
+		`![remote](https://example.com/a.png)`,
+		`![missing](gone.png)`,
+		`<img alt="x" src='https://cdn/root.gif'>`,
+		`[ref]: https://cdn/posts/images/ref.webp`,
+		`[doc]: ./other.md`,
+		`![in code](images/cover.png)`,
+	} {
+		if !strings.Contains(rewritten, line) {
+			t.Errorf("rewritten body lacks %s:\n%s", line, rewritten)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+// InstrumentGORM times every GORM statement through callbacks and exports the sql.DB pool stats
+func InstrumentGORM(m *Metrics, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := m.registry.Register(collectors.NewDBStatsCollector(sqlDB, "article_service")); err != nil {
+		return err
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", m.after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", m.after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", m.after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", m.after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", m.after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", m.after("raw"))
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (m *Metrics) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		m.dbDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			m.dbErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "article_service"
+
+type (
+	// Metrics owns the Prometheus registry and every collector the service exports
+	Metrics struct {
+		registry *prometheus.Registry
+
+		rpcHandled  *prometheus.CounterVec
+		rpcDuration *prometheus.HistogramVec
+
+		dbDuration *prometheus.HistogramVec
+		dbErrors   *prometheus.CounterVec
+
+		minioDuration *prometheus.HistogramVec
+		minioErrors   *prometheus.CounterVec
+
+		userServiceHandled  *prometheus.CounterVec
+		userServiceDuration *prometheus.HistogramVec
+	}
+)
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_server_handled_total",
+			Help:      "RPCs completed on the server, by method and status code.",
+		}, []string{"grpc_method", "grpc_code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_server_handling_seconds",
+			Help:      "RPC latency on the server.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"grpc_method"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "GORM statement latency, by operation and table.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "GORM statements that failed, record-not-found excluded.",
+		}, []string{"operation", "table"}),
+		minioDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "minio_operation_duration_seconds",
+			Help:      "MinIO operation latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		minioErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "minio_operation_errors_total",
+			Help:      "MinIO operations that failed.",
+		}, []string{"operation"}),
+		userServiceHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "user_service_requests_total",
+			Help:      "Calls to the user service, by method and status code.",
+		}, []string{"grpc_method", "grpc_code"}),
+		userServiceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "user_service_request_duration_seconds",
+			Help:      "Latency of calls to the user service.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"grpc_method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcHandled, m.rpcDuration,
+		m.dbDuration, m.dbErrors,
+		m.minioDuration, m.minioErrors,
+		m.userServiceHandled, m.userServiceDuration,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+)
+
+// instrumentedMinIO decorates MinIOStorage with latency and error metrics
+type instrumentedMinIO struct {
+	next    repos.MinIOStorage
+	metrics *Metrics
+}
+
+// InstrumentMinIO wraps a MinIOStorage so every operation is measured
+func InstrumentMinIO(next repos.MinIOStorage, m *Metrics) repos.MinIOStorage {
+	return &instrumentedMinIO{next: next, metrics: m}
+}
+
+func (s *instrumentedMinIO) CreateFile(ctx context.Context, fileName string, fileContent []byte) (string, string, error) {
+	start := time.Now()
+	name, url, err := s.next.CreateFile(ctx, fileName, fileContent)
+	s.metrics.observeMinIO("create_file", start, err)
+	return name, url, err
+}
+
+func (s *instrumentedMinIO) DeleteFile(ctx context.Context, fileName string) error {
+	start := time.Now()
+	err := s.next.DeleteFile(ctx, fileName)
+	s.metrics.observeMinIO("delete_file", start, err)
+	return err
+}
+
+func (s *instrumentedMinIO) GetFileURL(ctx context.Context, fileName string) (string, error) {
+	start := time.Now()
+	url, err := s.next.GetFileURL(ctx, fileName)
+	s.metrics.observeMinIO("get_file_url", start, err)
+	return url, err
+}
+
+func (s *instrumentedMinIO) PublicURL(fileName string) string {
+	return s.next.PublicURL(fileName)
+}
+
+func (s *instrumentedMinIO) WalkFiles(ctx context.Context, fn func(models.StoredFile) error) error {
+	start := time.Now()
+	err := s.next.WalkFiles(ctx, fn)
+	s.metrics.observeMinIO("walk_files", start, err)
+	return err
+}
+
+func (s *instrumentedMinIO) OpenFile(ctx context.Context, fileName string) (io.ReadCloser, models.StoredFile, error) {
+	start := time.Now()
+	r, info, err := s.next.OpenFile(ctx, fileName)
+	s.metrics.observeMinIO("open_file", start, err)
+	return r, info, err
+}
+
+func (s *instrumentedMinIO) PutFile(ctx context.Context, fileName string, r io.Reader, size int64) error {
+	start := time.Now()
+	err := s.next.PutFile(ctx, fileName, r, size)
+	s.metrics.observeMinIO("put_file", start, err)
+	return err
+}
+
+func (m *Metrics) observeMinIO(operation string, start time.Time, err error) {
+	m.minioDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.minioErrors.WithLabelValues(operation).Inc()
+	}
+}
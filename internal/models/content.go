@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/content"
+)
+
+var contentFormats = map[article_protos.ContentFormat]string{
+	article_protos.ContentFormat_CONTENT_FORMAT_PLAIN:    content.FormatPlain,
+	article_protos.ContentFormat_CONTENT_FORMAT_MARKDOWN: content.FormatMarkdown,
+	article_protos.ContentFormat_CONTENT_FORMAT_HTML:     content.FormatHTML,
+}
+
+// ContentFormatFromProto maps a proto content format to its stored name, unspecified yields false
+func ContentFormatFromProto(f article_protos.ContentFormat) (string, bool) {
+	name, ok := contentFormats[f]
+	return name, ok
+}
+
+// ContentFormatToProto maps a stored content format back to its proto value
+func ContentFormatToProto(name string) article_protos.ContentFormat {
+	for f, n := range contentFormats {
+		if n == name {
+			return f
+		}
+	}
+	return article_protos.ContentFormat_CONTENT_FORMAT_UNSPECIFIED
+}
@@ -0,0 +1,80 @@
+package models
+
+import "github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+
+const (
+	RewriteAllowed       = "allowed"
+	RewriteDisallowed    = "disallowed"
+	RewriteFollowersOnly = "followers_only"
+
+	LicenseAllRightsReserved = "all_rights_reserved"
+	LicenseCCBY              = "cc_by"
+	LicenseCCBYSA            = "cc_by_sa"
+)
+
+var (
+	rewritePolicies = map[article_protos.RewritePolicy]string{
+		article_protos.RewritePolicy_REWRITE_POLICY_ALLOWED:        RewriteAllowed,
+		article_protos.RewritePolicy_REWRITE_POLICY_DISALLOWED:     RewriteDisallowed,
+		article_protos.RewritePolicy_REWRITE_POLICY_FOLLOWERS_ONLY: RewriteFollowersOnly,
+	}
+
+	licenses = map[article_protos.License]string{
+		article_protos.License_LICENSE_ALL_RIGHTS_RESERVED: LicenseAllRightsReserved,
+		article_protos.License_LICENSE_CC_BY:               LicenseCCBY,
+		article_protos.License_LICENSE_CC_BY_SA:            LicenseCCBYSA,
+	}
+
+	licenseNames = map[string]string{
+		LicenseAllRightsReserved: "All rights reserved",
+		LicenseCCBY:              "CC BY 4.0",
+		LicenseCCBYSA:            "CC BY-SA 4.0",
+	}
+)
+
+// RewritePolicyFromProto maps a proto rewrite policy to its stored name, unspecified yields false
+func RewritePolicyFromProto(p article_protos.RewritePolicy) (string, bool) {
+	name, ok := rewritePolicies[p]
+	return name, ok
+}
+
+// RewritePolicyToProto maps a stored rewrite policy back to its proto value
+func RewritePolicyToProto(name string) article_protos.RewritePolicy {
+	for p, n := range rewritePolicies {
+		if n == name {
+			return p
+		}
+	}
+	return article_protos.RewritePolicy_REWRITE_POLICY_UNSPECIFIED
+}
+
+// LicenseFromProto maps a proto license to its stored name, unspecified yields false
+func LicenseFromProto(l article_protos.License) (string, bool) {
+	name, ok := licenses[l]
+	return name, ok
+}
+
+// LicenseToProto maps a stored license back to its proto value
+func LicenseToProto(name string) article_protos.License {
+	for l, n := range licenses {
+		if n == name {
+			return l
+		}
+	}
+	return article_protos.License_LICENSE_UNSPECIFIED
+}
+
+// LicenseName returns the human readable name of a stored license
+func LicenseName(name string) string {
+	return licenseNames[name]
+}
+
+// LicenseRequiresAttribution reports whether derived works must credit the original author
+func LicenseRequiresAttribution(name string) bool {
+	return name == LicenseCCBY || name == LicenseCCBYSA
+}
+
+// LicenseIsShareAlike reports whether derived works must carry the same license
+func LicenseIsShareAlike(name string) bool {
+	return name == LicenseCCBYSA
+}
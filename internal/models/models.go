@@ -4,40 +4,173 @@ import (
 	"time"
 
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/content"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	IdempotencyPending   = "pending"
+	IdempotencyCompleted = "completed"
+)
+
 type (
 	Article struct {
-		ID                string    `gorm:"primaryKey;type:uuid;"`
-		UserID            string    `gorm:"type:uuid;not null"`
-		OriginalArticleID string    `gorm:"type:uuid;default:null"`
-		Title             string    `gorm:"not null"`
-		Content           string    `gorm:"not null"`
-		CreatedAt         time.Time `gorm:"autoCreateTime"`
-		LikesCount        int       `gorm:"not null;default:0"`
-		Version           uint      `gorm:"default:1"`
-	}
-
-	ArticleLike struct {
-		UserID    string    `gorm:"type:uuid;not null;primaryKey"`
-		ArticleID string    `gorm:"type:uuid;not null;primaryKey"`
+		ID                 string    `gorm:"primaryKey;type:uuid;"`
+		UserID             string    `gorm:"type:uuid;not null"`
+		OriginalArticleID  string    `gorm:"type:uuid;default:null;index"`
+		Title              string    `gorm:"not null"`
+		Slug               string    `gorm:"type:varchar(128);default:null;uniqueIndex"`
+		Content            string    `gorm:"not null"`
+		ContentFormat      string    `gorm:"type:varchar(16);not null;default:plain"`
+		ContentHTML        string    `gorm:"column:content_html;not null;default:''"`
+		WordCount          int       `gorm:"not null;default:0"`
+		ReadingTimeMinutes int       `gorm:"not null;default:0"`
+		Excerpt            string    `gorm:"not null;default:''"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+		UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+		EditedAt           time.Time `gorm:"not null"` // moves on content edits only, unlike UpdatedAt
+		LikesCount         int       `gorm:"not null;default:0"`
+		RewritesCount      int       `gorm:"not null;default:0"`
+		RewritePolicy      string    `gorm:"type:varchar(32);not null;default:allowed"`
+		License            string    `gorm:"type:varchar(32);not null;default:all_rights_reserved"`
+		Attribution        string    `gorm:"not null;default:''"`
+		ContentRevision    int64     `gorm:"not null;default:1"`
+		Status             string    `gorm:"type:varchar(16);not null;default:published"`
+		Tags               Tags      `gorm:"type:jsonb;not null;default:'[]'"`
+		Version            uint      `gorm:"default:1"`
+	}
+
+	// ArticleSlug records every slug an article has had, old slugs keep resolving so links redirect
+	ArticleSlug struct {
+		Slug      string `gorm:"type:varchar(128);primaryKey"`
+		ArticleID string `gorm:"type:uuid;not null;index"`
+		// Base is the slug the title produced, it differs from Slug only for a collision suffix
+		Base      string    `gorm:"type:varchar(128);not null;index"`
 		CreatedAt time.Time `gorm:"autoCreateTime"`
 	}
 
+	ArticleReaction struct {
+		UserID       string    `gorm:"type:uuid;not null;primaryKey;index:idx_article_reactions_user_type_created,priority:1"`
+		ArticleID    string    `gorm:"type:uuid;not null;primaryKey;index:idx_article_reactions_article_type_created,priority:1"`
+		ReactionType string    `gorm:"type:varchar(32);not null;primaryKey;index:idx_article_reactions_article_type_created,priority:2;index:idx_article_reactions_user_type_created,priority:2"`
+		Count        int       `gorm:"not null;default:1"`
+		CreatedAt    time.Time `gorm:"autoCreateTime;index:idx_article_reactions_article_type_created,priority:3,sort:desc;index:idx_article_reactions_user_type_created,priority:3,sort:desc"`
+		UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	}
+
+	// ArticleReactionCount holds per-type aggregates, Total sums counts while Reactors counts users
+	ArticleReactionCount struct {
+		ArticleID    string `gorm:"type:uuid;not null;primaryKey"`
+		ReactionType string `gorm:"type:varchar(32);not null;primaryKey"`
+		Total        int    `gorm:"not null;default:0"`
+		Reactors     int    `gorm:"not null;default:0"`
+	}
+
+	// LineageArticle is an article found while walking rewrite links, Depth counts hops from the start
+	LineageArticle struct {
+		Article Article `gorm:"embedded"`
+		Depth   int
+	}
+
+	// LikedArticle is an article joined with the time the user liked it
+	LikedArticle struct {
+		Article Article `gorm:"embedded"`
+		LikedAt time.Time
+	}
+
+	// IdempotencyKey stores the outcome of a mutating request so retries with the same key replay it
+	IdempotencyKey struct {
+		Caller       string    `gorm:"type:varchar(255);primaryKey"` // the acting user, keys are scoped to it
+		Key          string    `gorm:"type:varchar(255);primaryKey"`
+		Method       string    `gorm:"type:varchar(255);primaryKey"`
+		Fingerprint  string    `gorm:"type:char(64);not null"`
+		Token        string    `gorm:"type:varchar(36);not null;default:''"` // identifies the claim that may complete the record
+		State        string    `gorm:"type:varchar(16);not null"`
+		ResponseType string    `gorm:"not null;default:''"`
+		Response     []byte    `gorm:"type:bytea"`
+		CreatedAt    time.Time `gorm:"not null"`
+		ExpiresAt    time.Time `gorm:"not null;index"`
+	}
+
+	// ReindexResult summarises a pass that rebuilds derived article columns
+	ReindexResult struct {
+		Scanned        int64 `json:"scanned"`
+		ContentUpdated int64 `json:"content_updated"`
+		SlugsAssigned  int64 `json:"slugs_assigned"`
+		DryRun         bool  `json:"dry_run"`
+	}
+
+	// SitemapPage is one rendered sitemap file, Fingerprint covers the URLs it was rendered from
+	SitemapPage struct {
+		Page         int       `gorm:"primaryKey;autoIncrement:false"`
+		URLCount     int       `gorm:"not null"`
+		Fingerprint  string    `gorm:"type:char(64);not null"`
+		LastModified time.Time `gorm:"not null"` // latest edit among the listed articles
+		Body         []byte    `gorm:"type:bytea;not null"`
+		GeneratedAt  time.Time `gorm:"not null"`
+	}
+
+	// SitemapEntry is a published article as the sitemap lists it
+	SitemapEntry struct {
+		ID        string
+		Slug      string
+		CreatedAt time.Time
+		EditedAt  time.Time
+	}
+
+	// StoredFile describes an object in the files bucket
+	StoredFile struct {
+		Name         string
+		Size         int64
+		LastModified time.Time
+	}
+
 	Picture struct {
-		FileName  string `gorm:"not null"`
-		ArticleID string `gorm:"not null"`
+		FileName  string `gorm:"not null;uniqueIndex:idx_pictures_file_article,priority:1"`
+		ArticleID string `gorm:"not null;uniqueIndex:idx_pictures_file_article,priority:2"`
 	}
 )
 
+// SetRendered stores the rendered body together with the reading metadata derived from it
+func (a *Article) SetRendered(format, rendered string) {
+	stats := content.Analyze(rendered)
+	a.ContentFormat = format
+	a.ContentHTML = rendered
+	a.WordCount = stats.WordCount
+	a.ReadingTimeMinutes = stats.ReadingTimeMinutes
+	a.Excerpt = stats.Excerpt
+}
+
+func (c *ArticleReactionCount) ToReactionCount() *article_protos.ReactionCount {
+	return &article_protos.ReactionCount{
+		ReactionType: ReactionTypeToProto(c.ReactionType),
+		Total:        int32(c.Total),
+		Reactors:     int32(c.Reactors),
+	}
+}
+
 func (a *Article) ToArticleEntity() *article_protos.ArticleEntity {
 	return &article_protos.ArticleEntity{
-		Id:        a.ID,
-		UserId:    a.UserID,
-		Title:     a.Title,
-		Content:   a.Content,
-		CreatedAt: timestamppb.New(a.CreatedAt),
-		LikeCount: int32(a.LikesCount),
+		Id:                 a.ID,
+		UserId:             a.UserID,
+		OriginalArticleId:  a.OriginalArticleID,
+		Title:              a.Title,
+		Slug:               a.Slug,
+		Content:            a.Content,
+		ContentFormat:      ContentFormatToProto(a.ContentFormat),
+		ContentHtml:        a.ContentHTML,
+		CreatedAt:          timestamppb.New(a.CreatedAt),
+		UpdatedAt:          timestamppb.New(a.UpdatedAt),
+		WordCount:          int32(a.WordCount),
+		ReadingTimeMinutes: int32(a.ReadingTimeMinutes),
+		Excerpt:            a.Excerpt,
+		LikeCount:          int32(a.LikesCount),
+		RewritesCount:      int32(a.RewritesCount),
+		RewritePolicy:      RewritePolicyToProto(a.RewritePolicy),
+		License:            LicenseToProto(a.License),
+		Attribution:        a.Attribution,
+		ContentRevision:    a.ContentRevision,
+		Status:             StatusToProto(a.Status),
+		Tags:               a.Tags,
 	}
 }
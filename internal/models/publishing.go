@@ -0,0 +1,81 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/slug"
+)
+
+const (
+	ArticlePublished = "published"
+	ArticleDraft     = "draft"
+
+	// MaxTags is how many tags an article may carry
+	MaxTags = 10
+)
+
+var statuses = map[article_protos.ArticleStatus]string{
+	article_protos.ArticleStatus_ARTICLE_STATUS_PUBLISHED: ArticlePublished,
+	article_protos.ArticleStatus_ARTICLE_STATUS_DRAFT:     ArticleDraft,
+}
+
+// StatusFromProto maps a proto article status to its stored name, unspecified yields false
+func StatusFromProto(s article_protos.ArticleStatus) (string, bool) {
+	name, ok := statuses[s]
+	return name, ok
+}
+
+// StatusToProto maps a stored article status back to its proto value
+func StatusToProto(name string) article_protos.ArticleStatus {
+	for s, n := range statuses {
+		if n == name {
+			return s
+		}
+	}
+	return article_protos.ArticleStatus_ARTICLE_STATUS_UNSPECIFIED
+}
+
+// Tags is stored as a jsonb array so articles can be looked up by tag through a GIN index
+type Tags []string
+
+// NormalizeTags turns tags into slugs and drops empty and repeated ones, keeping their order
+func NormalizeTags(tags []string) Tags {
+	normalized := make(Tags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = slug.Tag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(t))
+	return string(b), err
+}
+
+func (t *Tags) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
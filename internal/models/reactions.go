@@ -0,0 +1,52 @@
+package models
+
+import "github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+
+const (
+	ReactionLike       = "like"
+	ReactionClap       = "clap"
+	ReactionHeart      = "heart"
+	ReactionLaugh      = "laugh"
+	ReactionFire       = "fire"
+	ReactionInsightful = "insightful"
+)
+
+var (
+	reactionTypes = map[article_protos.ReactionType]string{
+		article_protos.ReactionType_REACTION_TYPE_LIKE:       ReactionLike,
+		article_protos.ReactionType_REACTION_TYPE_CLAP:       ReactionClap,
+		article_protos.ReactionType_REACTION_TYPE_HEART:      ReactionHeart,
+		article_protos.ReactionType_REACTION_TYPE_LAUGH:      ReactionLaugh,
+		article_protos.ReactionType_REACTION_TYPE_FIRE:       ReactionFire,
+		article_protos.ReactionType_REACTION_TYPE_INSIGHTFUL: ReactionInsightful,
+	}
+
+	// reactionCaps is how many times a single user may apply a reaction type
+	reactionCaps = map[string]int{
+		ReactionClap: 50,
+	}
+)
+
+// ReactionTypeFromProto maps a proto reaction type to its stored name
+func ReactionTypeFromProto(t article_protos.ReactionType) (string, bool) {
+	name, ok := reactionTypes[t]
+	return name, ok
+}
+
+// ReactionTypeToProto maps a stored reaction name back to its proto value
+func ReactionTypeToProto(name string) article_protos.ReactionType {
+	for t, n := range reactionTypes {
+		if n == name {
+			return t
+		}
+	}
+	return article_protos.ReactionType_REACTION_TYPE_UNSPECIFIED
+}
+
+// ReactionCap returns the per-user limit for a reaction type
+func ReactionCap(name string) int {
+	if c, ok := reactionCaps[name]; ok {
+		return c
+	}
+	return 1
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// fallbackLimiter uses the primary limiter and switches to the secondary while the primary errors.
+// Each primary call is bounded by timeout, and after a failure the primary is left alone for
+// cooldown so an outage costs one slow call per cooldown instead of one per request
+type fallbackLimiter struct {
+	primary   Limiter
+	secondary Limiter
+	timeout   time.Duration
+	cooldown  time.Duration
+	onError   func(error)
+	openUntil atomic.Int64 // unix nanoseconds until which the primary is skipped
+	now       func() time.Time
+}
+
+// WithFallback returns a Limiter that falls back to secondary whenever primary fails or takes
+// longer than timeout, and stays on secondary for cooldown afterwards. onError is told about each
+// failure
+func WithFallback(primary, secondary Limiter, timeout, cooldown time.Duration, onError func(error)) Limiter {
+	return &fallbackLimiter{
+		primary:   primary,
+		secondary: secondary,
+		timeout:   timeout,
+		cooldown:  cooldown,
+		onError:   onError,
+		now:       time.Now,
+	}
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if l.now().UnixNano() < l.openUntil.Load() {
+		return l.secondary.Allow(ctx, key, limit)
+	}
+
+	primaryCtx := ctx
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		primaryCtx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+	allowed, retry, err := l.primary.Allow(primaryCtx, key, limit)
+	if err == nil {
+		return allowed, retry, nil
+	}
+	if ctx.Err() != nil {
+		// the caller went away, that says nothing about the primary
+		return false, 0, ctx.Err()
+	}
+	l.openUntil.Store(l.now().Add(l.cooldown).UnixNano())
+	if l.onError != nil {
+		l.onError(err)
+	}
+	return l.secondary.Allow(ctx, key, limit)
+}
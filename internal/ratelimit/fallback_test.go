@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubLimiter counts calls and either fails or blocks until its context is done
+type stubLimiter struct {
+	calls int
+	fail  bool
+	block bool
+}
+
+func (s *stubLimiter) Allow(ctx context.Context, _ string, _ Limit) (bool, time.Duration, error) {
+	s.calls++
+	if s.block {
+		<-ctx.Done()
+		return false, 0, ctx.Err()
+	}
+	if s.fail {
+		return false, 0, errors.New("connection refused")
+	}
+	return true, 0, nil
+}
+
+func TestFallbackCooldown(t *testing.T) {
+	now := time.Unix(0, 0)
+	primary, secondary := &stubLimiter{fail: true}, &stubLimiter{}
+	var failures int
+	l := WithFallback(primary, secondary, time.Second, 30*time.Second, func(error) { failures++ }).(*fallbackLimiter)
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ok, _, err := l.Allow(ctx, "k", Limit{Rate: 1, Burst: 1}); !ok || err != nil {
+			t.Fatalf("call %d: allowed %v, err %v", i, ok, err)
+		}
+	}
+	if primary.calls != 1 || secondary.calls != 3 || failures != 1 {
+		t.Errorf("during the cooldown: primary %d calls, secondary %d, failures %d", primary.calls, secondary.calls, failures)
+	}
+
+	primary.fail = false
+	now = now.Add(30 * time.Second)
+	if _, _, err := l.Allow(ctx, "k", Limit{Rate: 1, Burst: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if primary.calls != 2 || secondary.calls != 3 {
+		t.Errorf("after the cooldown the primary should be tried again, primary %d calls, secondary %d", primary.calls, secondary.calls)
+	}
+}
+
+func TestFallbackTimeout(t *testing.T) {
+	primary, secondary := &stubLimiter{block: true}, &stubLimiter{}
+	l := WithFallback(primary, secondary, 10*time.Millisecond, time.Minute, nil)
+
+	start := time.Now()
+	if ok, _, err := l.Allow(context.Background(), "k", Limit{Rate: 1, Burst: 1}); !ok || err != nil {
+		t.Fatalf("allowed %v, err %v", ok, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("a hanging primary held the call for %v", elapsed)
+	}
+	if secondary.calls != 1 {
+		t.Errorf("secondary called %d times, want 1", secondary.calls)
+	}
+}
+
+func TestFallbackCallerCancelled(t *testing.T) {
+	primary, secondary := &stubLimiter{block: true}, &stubLimiter{}
+	l := WithFallback(primary, secondary, time.Second, time.Minute, nil).(*fallbackLimiter)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := l.Allow(ctx, "k", Limit{Rate: 1, Burst: 1}); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if secondary.calls != 0 || l.openUntil.Load() != 0 {
+		t.Error("a cancelled caller should not trip the fallback")
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepThreshold = 10_000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// memoryLimiter keeps buckets in process, limits then hold per replica only
+type memoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.buckets) >= sweepThreshold {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, b.last, now, limit)
+	b.last = now
+	if b.tokens < 1 {
+		return false, retryAfter(b.tokens, limit), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep drops buckets idle long enough to be full again, keeping memory bounded
+func (l *memoryLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > time.Hour {
+			delete(l.buckets, key)
+		}
+	}
+}
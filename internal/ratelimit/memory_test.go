@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := &memoryLimiter{buckets: make(map[string]*bucket), now: func() time.Time { return now }}
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.Allow(ctx, "k", limit); !ok {
+			t.Fatalf("request %d within burst was rejected", i)
+		}
+	}
+	ok, retry, _ := l.Allow(ctx, "k", limit)
+	if ok {
+		t.Fatal("request beyond burst was allowed")
+	}
+	if retry != time.Second {
+		t.Errorf("retry after = %v, want 1s", retry)
+	}
+	if ok, _, _ := l.Allow(ctx, "other", limit); !ok {
+		t.Error("buckets are not isolated per key")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _, _ := l.Allow(ctx, "k", limit); !ok {
+		t.Error("bucket did not refill")
+	}
+}
+
+func TestParseMethodLimits(t *testing.T) {
+	limits, err := ParseMethodLimits("CreateArticle=30:10, LikeArticle=120:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := limits["CreateArticle"]; got.Rate != 0.5 || got.Burst != 10 {
+		t.Errorf("CreateArticle = %+v", got)
+	}
+	if _, err := ParseMethodLimits("CreateArticle=30"); err == nil {
+		t.Error("expected an error for a limit without burst")
+	}
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Limit is a token bucket: Rate tokens are added per second up to Burst
+	Limit struct {
+		Rate  float64
+		Burst int
+	}
+
+	// Limiter takes one token from the bucket identified by key
+	Limiter interface {
+		Allow(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+	}
+)
+
+// ParseLimit parses "<per minute>:<burst>", e.g. "30:10"
+func ParseLimit(s string) (Limit, error) {
+	perMinute, burst, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want <per minute>:<burst>", s)
+	}
+	rate, err := strconv.ParseFloat(perMinute, 64)
+	if err != nil || rate <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate in %q", s)
+	}
+	b, err := strconv.Atoi(burst)
+	if err != nil || b <= 0 {
+		return Limit{}, fmt.Errorf("invalid burst in %q", s)
+	}
+	return Limit{Rate: rate / 60, Burst: b}, nil
+}
+
+// ParseMethodLimits parses a comma separated list of "<method>=<per minute>:<burst>" overrides,
+// methods are bare RPC names such as CreateArticle
+func ParseMethodLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		method, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid method rate limit %q, want <method>=<per minute>:<burst>", entry)
+		}
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.TrimSpace(method)] = limit
+	}
+	return limits, nil
+}
+
+// refill computes the bucket state at now, shared by the in-memory limiter and mirrored by the Redis script
+func refill(tokens float64, last, now time.Time, limit Limit) float64 {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed > 0 {
+		tokens += elapsed * limit.Rate
+	}
+	return min(tokens, float64(limit.Burst))
+}
+
+func retryAfter(tokens float64, limit Limit) time.Duration {
+	return time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes from a bucket atomically using the Redis clock,
+// so every replica shares one bucket per key. Returns {allowed, retry after in ms}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+if now > ts then
+  tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, retry}
+`)
+
+// redisLimiter shares buckets between replicas through Redis
+type redisLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisLimiter(client *redis.Client) Limiter {
+	return &redisLimiter{client: client}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	// a bucket idle for the time it takes to refill completely carries no state worth keeping
+	ttl := int64(math.Ceil(float64(limit.Burst)/limit.Rate*1000)) + 1000
+	res, err := tokenBucketScript.Run(ctx, l.client, []string{key}, limit.Rate, limit.Burst, ttl).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,29 @@
+package repos
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
+
+// ArchiveVisitor receives rows during an export, a nil callback skips its table
+type ArchiveVisitor struct {
+	Article       func(*models.Article) error
+	Slug          func(*models.ArticleSlug) error
+	Reaction      func(*models.ArticleReaction) error
+	ReactionCount func(*models.ArticleReactionCount) error
+	Picture       func(*models.Picture) error
+}
+
+// ArchiveStore reads and writes raw rows for logical export and import. Imports keep primary keys
+// and skip rows that already exist, returning how many were new, a dry run rolls back after counting
+type ArchiveStore interface {
+	// Export streams every archived row to v from one consistent read-only snapshot, articles first
+	// then slugs, reactions, reaction counts and pictures
+	Export(ctx context.Context, v ArchiveVisitor) error
+	ImportArticles(ctx context.Context, articles []*models.Article, dryRun bool) (int64, error)
+	ImportSlugs(ctx context.Context, slugs []*models.ArticleSlug, dryRun bool) (int64, error)
+	ImportReactions(ctx context.Context, reactions []*models.ArticleReaction, dryRun bool) (int64, error)
+	ImportReactionCounts(ctx context.Context, counts []*models.ArticleReactionCount, dryRun bool) (int64, error)
+	ImportPictures(ctx context.Context, pictures []*models.Picture, dryRun bool) (int64, error)
+}
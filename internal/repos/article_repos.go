@@ -2,19 +2,36 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
 )
 
 type ArticleRepo interface {
 	CreateArticle(context.Context, *article_protos.CreateArticleRequest) (*article_protos.ArticleEntity, error)
+	// CreateImportedArticle is CreateArticle keeping the article's original date, a zero createdAt means now.
+	// The pictures of the already uploaded images are recorded in the same transaction
+	CreateImportedArticle(ctx context.Context, in *article_protos.CreateArticleRequest, createdAt time.Time, pictures []string) (*article_protos.ArticleEntity, error)
 	UpdateArticle(context.Context, *article_protos.UpdateArticleRequest) (*article_protos.ArticleEntity, error)
-	RewriteArticle(context.Context, *article_protos.RewriteArticleRequest) (*article_protos.ArticleEntity, error)
+	RewriteArticle(context.Context, *article_protos.RewriteArticleRequest, string) (*article_protos.ArticleEntity, error)
 	DeleteArticle(context.Context, *article_protos.DeleteArticleRequest) (*article_protos.DeleteArticleResponse, error)
 	LikeArticle(context.Context, *article_protos.LikeArticleRequest) (*article_protos.LikeArticleResponse, error)
 	UnlikeArticle(context.Context, *article_protos.UnlikeArticleRequest) (*article_protos.UnlikeArticleResponse, error)
 	GetArticlesByUser(context.Context, *article_protos.GetArticlesByUserRequest) (*article_protos.GetArticlesByUserResponse, error)
 	GetArticles(context.Context, *article_protos.GetArticlesRequest) (*article_protos.GetArticlesResponse, error)
 	GetArticleByID(context.Context, *article_protos.GetArticleByIDRequest) (*article_protos.GetArticleByIDResponse, error)
+	ResolveSlug(context.Context, string) (string, error)
 	HasUserLikedArticle(context.Context, string, string) (bool, error)
+	ReconcileLikes(context.Context, *article_protos.ReconcileLikesRequest) (*article_protos.ReconcileLikesResponse, error)
+	ReindexArticles(ctx context.Context, batchSize int, dryRun bool) (*models.ReindexResult, error)
+	ListArticleLikers(context.Context, *article_protos.ListArticleLikersRequest) (*article_protos.ListArticleLikersResponse, error)
+	ListLikedArticles(context.Context, *article_protos.ListLikedArticlesRequest) (*article_protos.ListLikedArticlesResponse, error)
+	AddReaction(context.Context, *article_protos.AddReactionRequest) (*article_protos.ReactionResponse, error)
+	RemoveReaction(context.Context, *article_protos.RemoveReactionRequest) (*article_protos.ReactionResponse, error)
+	ListArticleRewrites(context.Context, *article_protos.ListArticleRewritesRequest) (*article_protos.ListArticleRewritesResponse, error)
+	GetArticleAncestry(context.Context, *article_protos.GetArticleAncestryRequest) (*article_protos.GetArticleAncestryResponse, error)
+	GetDerivationTree(context.Context, *article_protos.GetDerivationTreeRequest) (*article_protos.GetDerivationTreeResponse, error)
+	// FeedArticles returns the newest published articles of an author or, when tag is set, of a tag
+	FeedArticles(ctx context.Context, userID, tag string, limit int) ([]*models.Article, error)
 }
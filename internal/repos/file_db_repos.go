@@ -11,4 +11,10 @@ type PictureRepo interface {
 	GetPicture(ctx context.Context, fileName, articleID string) (*models.Picture, error)
 	GetPicturesByArticle(ctx context.Context, articleID string) ([]*models.Picture, error)
 	DeletePicture(ctx context.Context, fileName, articleID string) error
+	// ListOrphanPictures returns pictures whose article no longer exists
+	ListOrphanPictures(ctx context.Context) ([]*models.Picture, error)
+	// ReferencedFiles reports which of the given object names are still used by a picture
+	ReferencedFiles(ctx context.Context, fileNames []string) (map[string]bool, error)
+	// PublishedFiles reports which of the given object names belong to a published article
+	PublishedFiles(ctx context.Context, fileNames []string) (map[string]bool, error)
 }
@@ -1,9 +1,22 @@
 package repos
 
-import "context"
+import (
+	"context"
+	"io"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
 
 type MinIOStorage interface {
 	CreateFile(ctx context.Context, fileName string, fileContent []byte) (string, string, error)
 	DeleteFile(ctx context.Context, fileName string) error
 	GetFileURL(ctx context.Context, fileName string) (string, error)
+	// PublicURL is the permanent address of an object, for links embedded in article bodies
+	PublicURL(fileName string) string
+	// WalkFiles calls fn for every object in the bucket, stopping at the first error
+	WalkFiles(ctx context.Context, fn func(models.StoredFile) error) error
+	// OpenFile streams an object, a missing object returns an error wrapping fs.ErrNotExist
+	OpenFile(ctx context.Context, fileName string) (io.ReadCloser, models.StoredFile, error)
+	// PutFile stores an object under exactly the given name, replacing any existing one
+	PutFile(ctx context.Context, fileName string, r io.Reader, size int64) error
 }
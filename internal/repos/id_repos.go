@@ -0,0 +1,7 @@
+package repos
+
+// IDGenerator produces primary keys for new rows, ids must be unique across goroutines and replicas
+// and sort by creation order
+type IDGenerator interface {
+	NewID() string
+}
@@ -0,0 +1,18 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
+
+type IdempotencyStore interface {
+	// Reserve claims key for method on behalf of caller, returning true when the request should run.
+	// Otherwise the existing record is returned for replay or conflict detection
+	Reserve(ctx context.Context, caller, key, method, fingerprint string, ttl, pendingTimeout time.Duration) (*models.IdempotencyKey, bool, error)
+	// Complete and Release only touch the record while it still holds claim's token
+	Complete(ctx context.Context, claim *models.IdempotencyKey, responseType string, response []byte) error
+	Release(ctx context.Context, claim *models.IdempotencyKey) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,23 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
+
+type SitemapStore interface {
+	// PublishedSummary counts the published articles and finds the latest edit among them
+	PublishedSummary(ctx context.Context) (int64, time.Time, error)
+	// SitemapEntries returns up to limit published articles oldest first, starting after the
+	// (afterCreated, afterID) position, an empty afterID starts at the beginning
+	SitemapEntries(ctx context.Context, afterCreated time.Time, afterID string, limit int) ([]models.SitemapEntry, error)
+	// SitemapPages lists the stored pages in order, without their bodies
+	SitemapPages(ctx context.Context) ([]models.SitemapPage, error)
+	// SitemapPage returns a stored page with its body, codes.NotFound when there is none
+	SitemapPage(ctx context.Context, page int) (*models.SitemapPage, error)
+	SaveSitemapPage(ctx context.Context, page *models.SitemapPage) error
+	// DeleteSitemapPages removes the pages numbered from and above
+	DeleteSitemapPages(ctx context.Context, from int) (int64, error)
+}
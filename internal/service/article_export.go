@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/articleexport"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contentDispositionHeader carries the suggested file name of an export, the HTTP gateway passes it on
+const contentDispositionHeader = "content-disposition"
+
+// ExportArticle renders an article with its author, date and images into a single downloadable file,
+// a draft is exported only for its author
+func (a *ArticleService) ExportArticle(ctx context.Context, req *article_protos.ExportArticleRequest) (*httpbody.HttpBody, error) {
+	resp, err := a.storage.GetArticleByID(ctx, &article_protos.GetArticleByIDRequest{ArticleId: req.ArticleId, ViewerId: req.ViewerId})
+	if err != nil {
+		a.logger.Error("failed to fetch article for export", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
+		return nil, err
+	}
+	article := resp.Article
+	if err := a.fillArticleEntity(ctx, article, article.UserId); err != nil {
+		return nil, err
+	}
+	images, err := a.articleImages(ctx, article.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var body *httpbody.HttpBody
+	var fileName string
+	switch req.Format {
+	case article_protos.ExportFormat_EXPORT_FORMAT_UNSPECIFIED, article_protos.ExportFormat_EXPORT_FORMAT_HTML:
+		fileName = articleexport.FileName(article, ".html")
+		body = &httpbody.HttpBody{ContentType: articleexport.HTMLContentType}
+		body.Data, err = articleexport.HTML(article, images)
+	case article_protos.ExportFormat_EXPORT_FORMAT_MARKDOWN:
+		fileName = articleexport.FileName(article, ".zip")
+		body = &httpbody.HttpBody{ContentType: articleexport.ZipContentType}
+		body.Data, err = articleexport.MarkdownZip(article, images)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown format")
+	}
+	if err != nil {
+		a.logger.Error("failed to render article export", logging.Fields(ctx, map[string]any{"article_id": article.Id, "format": req.Format.String(), "error": err.Error()}))
+		return nil, status.Errorf(codes.Internal, "failed to export article: %v", err)
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+	if err := grpc.SetHeader(ctx, metadata.Pairs(contentDispositionHeader, disposition)); err != nil {
+		a.logger.Error("failed to set content-disposition header", logging.Fields(ctx, map[string]any{"article_id": article.Id, "error": err.Error()}))
+	}
+	return body, nil
+}
+
+// articleImages loads the bytes of every picture attached to an article, keyed by the URL article
+// bodies use for them
+func (a *ArticleService) articleImages(ctx context.Context, articleID string) ([]articleexport.Image, error) {
+	pictures, err := a.fileDbStorage.GetPicturesByArticle(ctx, articleID)
+	if err != nil {
+		a.logger.Error("failed to fetch pictures for article", logging.Fields(ctx, map[string]any{"article_id": articleID, "error": err.Error()}))
+		return nil, err
+	}
+	images := make([]articleexport.Image, 0, len(pictures))
+	for _, picture := range pictures {
+		data, err := a.readFile(ctx, picture.FileName)
+		if errors.Is(err, fs.ErrNotExist) {
+			// the export stays useful without an image that was lost from the bucket
+			a.logger.Error("picture missing from MinIO", logging.Fields(ctx, map[string]any{"file_name": picture.FileName, "article_id": articleID}))
+			continue
+		}
+		if err != nil {
+			a.logger.Error("failed to read file from MinIO", logging.Fields(ctx, map[string]any{"file_name": picture.FileName, "article_id": articleID, "error": err.Error()}))
+			return nil, status.Errorf(codes.Internal, "failed to read image %s", picture.FileName)
+		}
+		images = append(images, articleexport.Image{
+			Name: picture.FileName,
+			URL:  a.filesStorage.PublicURL(picture.FileName),
+			Data: data,
+		})
+	}
+	return images, nil
+}
+
+func (a *ArticleService) readFile(ctx context.Context, fileName string) ([]byte, error) {
+	r, _, err := a.filesStorage.OpenFile(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// draftArticles holds one draft written by u-1 and, like the storage, only shows it to its author
+type draftArticles struct {
+	repos.ArticleRepo
+}
+
+func (draftArticles) GetArticleByID(_ context.Context, in *article_protos.GetArticleByIDRequest) (*article_protos.GetArticleByIDResponse, error) {
+	if in.ViewerId != "u-1" {
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+	return &article_protos.GetArticleByIDResponse{Article: &article_protos.ArticleEntity{Id: in.ArticleId, UserId: "u-1"}}, nil
+}
+
+func TestExportArticleHidesDrafts(t *testing.T) {
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewArticleService(draftArticles{}, log, nil, nil, nil)
+
+	for _, viewer := range []string{"", "u-2"} {
+		_, err := svc.ExportArticle(context.Background(), &article_protos.ExportArticleRequest{ArticleId: "a-1", ViewerId: viewer})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("viewer %q: got %v, want NotFound", viewer, err)
+		}
+	}
+}
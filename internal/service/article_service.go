@@ -6,6 +6,7 @@ import (
 
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
 	"github.com/ruziba3vich/mm_article_service/internal/models"
 	"github.com/ruziba3vich/mm_article_service/internal/repos"
 	logger "github.com/ruziba3vich/prodonik_lgger"
@@ -41,7 +42,7 @@ func NewArticleService(storage repos.ArticleRepo,
 func (a *ArticleService) CreateArticle(ctx context.Context, req *article_protos.CreateArticleRequest) (*article_protos.ArticleEntity, error) {
 	article, err := a.storage.CreateArticle(ctx, req)
 	if err != nil {
-		a.logger.Error("failed to create article", map[string]any{"user_id": req.UserId, "error": err.Error()})
+		a.logger.Error("failed to create article", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "error": err.Error()}))
 		return nil, err
 	}
 	files := make([]*article_protos.FileEntity, len(req.Files))
@@ -49,7 +50,7 @@ func (a *ArticleService) CreateArticle(ctx context.Context, req *article_protos.
 	for i := range req.Files {
 		fileName, url, err := a.filesStorage.CreateFile(ctx, req.Files[i].Name, req.Files[i].Content)
 		if err != nil {
-			a.logger.Error("failed to create file in MinIO", map[string]any{"file_name": req.Files[i].Name, "error": err.Error()})
+			a.logger.Error("failed to create file in MinIO", logging.Fields(ctx, map[string]any{"file_name": req.Files[i].Name, "error": err.Error()}))
 			return nil, err
 		}
 		files[i] = &article_protos.FileEntity{
@@ -57,13 +58,13 @@ func (a *ArticleService) CreateArticle(ctx context.Context, req *article_protos.
 			Url:      url,
 		}
 		if err := a.fileDbStorage.CreatePicture(ctx, &models.Picture{FileName: fileName, ArticleID: article.Id}); err != nil {
-			a.logger.Error("failed to store picture in database", map[string]any{"file_name": fileName, "article_id": article.Id, "error": err.Error()})
+			a.logger.Error("failed to store picture in database", logging.Fields(ctx, map[string]any{"file_name": fileName, "article_id": article.Id, "error": err.Error()}))
 			return nil, err
 		}
 	}
 	article.Files = files
 	if err := a.fillArticleEntity(ctx, article, req.UserId); err != nil {
-		a.logger.Error("failed to fill article entity", map[string]any{"user_id": req.UserId, "article_id": article.Id, "error": err.Error()})
+		a.logger.Error("failed to fill article entity", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": article.Id, "error": err.Error()}))
 		return nil, err
 	}
 
@@ -71,24 +72,24 @@ func (a *ArticleService) CreateArticle(ctx context.Context, req *article_protos.
 }
 
 func (a *ArticleService) DeleteArticle(ctx context.Context, req *article_protos.DeleteArticleRequest) (*article_protos.DeleteArticleResponse, error) {
-	article, err := a.storage.GetArticleByID(ctx, &article_protos.GetArticleByIDRequest{ArticleId: req.ArticleId})
+	article, err := a.storage.GetArticleByID(ctx, &article_protos.GetArticleByIDRequest{ArticleId: req.ArticleId, ViewerId: req.UserId})
 	if err != nil {
-		a.logger.Error("failed to fetch article for deletion", map[string]any{"article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("failed to fetch article for deletion", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
 		return nil, fmt.Errorf("could not fetch article: %s", err.Error())
 	}
 	go func() {
 		for i := range article.Article.Files {
 			if err := a.filesStorage.DeleteFile(ctx, article.Article.Files[i].FileName); err != nil {
-				a.logger.Error("failed to delete file from MinIO", map[string]any{"file_name": article.Article.Files[i].FileName, "error": err.Error()})
+				a.logger.Error("failed to delete file from MinIO", logging.Fields(ctx, map[string]any{"file_name": article.Article.Files[i].FileName, "error": err.Error()}))
 			}
 			if err := a.fileDbStorage.DeletePicture(ctx, article.Article.Files[i].FileName, article.Article.Id); err != nil {
-				a.logger.Error("failed to delete picture from database", map[string]any{"file_name": article.Article.Files[i].FileName, "article_id": article.Article.Id, "error": err.Error()})
+				a.logger.Error("failed to delete picture from database", logging.Fields(ctx, map[string]any{"file_name": article.Article.Files[i].FileName, "article_id": article.Article.Id, "error": err.Error()}))
 			}
 		}
 	}()
 	resp, err := a.storage.DeleteArticle(ctx, req)
 	if err != nil {
-		a.logger.Error("failed to delete article", map[string]any{"article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("failed to delete article", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	}
 	return resp, nil
@@ -97,62 +98,47 @@ func (a *ArticleService) DeleteArticle(ctx context.Context, req *article_protos.
 func (a *ArticleService) GetArticleByID(ctx context.Context, req *article_protos.GetArticleByIDRequest) (*article_protos.GetArticleByIDResponse, error) {
 	article, err := a.storage.GetArticleByID(ctx, req)
 	if err != nil {
-		a.logger.Error("failed to fetch article by ID", map[string]any{"article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("failed to fetch article by ID", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	}
 
-	files, err := a.fileDbStorage.GetPicturesByArticle(ctx, article.Article.Id)
-	if err != nil {
-		a.logger.Error("failed to fetch pictures for article", map[string]any{"article_id": article.Article.Id, "error": err.Error()})
+	if err := a.fillArticlePage(ctx, req.View, []*article_protos.ArticleEntity{article.Article}); err != nil {
 		return nil, err
 	}
-	for i := range files {
-		fileUrl, err := a.filesStorage.GetFileURL(ctx, files[i].FileName)
-		if err != nil {
-			a.logger.Error("failed to get file URL from MinIO", map[string]any{"file_name": files[i].FileName, "article_id": article.Article.Id, "error": err.Error()})
-			return nil, err
-		}
-		article.Article.Files = append(article.Article.Files, &article_protos.FileEntity{
-			FileName: files[i].FileName,
-			Url:      fileUrl,
-		})
-	}
 
-	if err := a.fillArticleEntity(ctx, article.Article, article.Article.UserId); err != nil {
-		a.logger.Error("failed to fill article entity", map[string]any{"user_id": article.Article.UserId, "article_id": article.Article.Id, "error": err.Error()})
+	return article, nil
+}
+
+// GetArticleBySlug resolves a current or historical slug and returns the same payload as GetArticleByID
+func (a *ArticleService) GetArticleBySlug(ctx context.Context, req *article_protos.GetArticleBySlugRequest) (*article_protos.GetArticleBySlugResponse, error) {
+	articleID, err := a.storage.ResolveSlug(ctx, req.Slug)
+	if err != nil {
+		a.logger.Error("failed to resolve article slug", logging.Fields(ctx, map[string]any{"slug": req.Slug, "error": err.Error()}))
 		return nil, err
 	}
 
-	return article, nil
+	resp, err := a.GetArticleByID(ctx, &article_protos.GetArticleByIDRequest{
+		ArticleId: articleID,
+		View:      req.View,
+		ViewerId:  req.ViewerId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &article_protos.GetArticleBySlugResponse{
+		Article:  resp.Article,
+		Redirect: resp.Article.Slug != req.Slug,
+	}, nil
 }
 
 func (a *ArticleService) GetArticles(ctx context.Context, req *article_protos.GetArticlesRequest) (*article_protos.GetArticlesResponse, error) {
 	resp, err := a.storage.GetArticles(ctx, req)
 	if err != nil {
-		a.logger.Error("failed to fetch articles", map[string]any{"page": req.Pagination.Page, "page_size": req.Pagination.PageSize, "error": err.Error()})
+		a.logger.Error("failed to fetch articles", logging.Fields(ctx, map[string]any{"page": req.Pagination.Page, "page_size": req.Pagination.PageSize, "error": err.Error()}))
 		return nil, err
 	}
-	for i := range resp.Pagination.Articles {
-		if err := a.fillArticleEntity(ctx, resp.Pagination.Articles[i], resp.Pagination.Articles[i].UserId); err != nil {
-			a.logger.Error("failed to fill article entity", map[string]any{"user_id": resp.Pagination.Articles[i].UserId, "article_id": resp.Pagination.Articles[i].Id, "error": err.Error()})
-			return nil, err
-		}
-		files, err := a.fileDbStorage.GetPicturesByArticle(ctx, resp.Pagination.Articles[i].Id)
-		if err != nil {
-			a.logger.Error("failed to fetch pictures for article", map[string]any{"article_id": resp.Pagination.Articles[i].Id, "error": err.Error()})
-			return nil, err
-		}
-		for j := range files {
-			fileUrl, err := a.filesStorage.GetFileURL(ctx, files[j].FileName)
-			if err != nil {
-				a.logger.Error("failed to get file URL from MinIO", map[string]any{"file_name": files[j].FileName, "article_id": resp.Pagination.Articles[i].Id, "error": err.Error()})
-				return nil, err
-			}
-			resp.Pagination.Articles[i].Files = append(resp.Pagination.Articles[i].Files, &article_protos.FileEntity{
-				FileName: files[j].FileName,
-				Url:      fileUrl,
-			})
-		}
+	if err := a.fillArticlePage(ctx, req.View, resp.Pagination.Articles); err != nil {
+		return nil, err
 	}
 
 	return resp, nil
@@ -161,30 +147,11 @@ func (a *ArticleService) GetArticles(ctx context.Context, req *article_protos.Ge
 func (a *ArticleService) GetArticlesByUser(ctx context.Context, req *article_protos.GetArticlesByUserRequest) (*article_protos.GetArticlesByUserResponse, error) {
 	resp, err := a.storage.GetArticlesByUser(ctx, req)
 	if err != nil {
-		a.logger.Error("failed to fetch articles by user", map[string]any{"user_id": req.UserId, "page": req.Pagination.Page, "page_size": req.Pagination.PageSize, "error": err.Error()})
+		a.logger.Error("failed to fetch articles by user", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "page": req.Pagination.Page, "page_size": req.Pagination.PageSize, "error": err.Error()}))
 		return nil, err
 	}
-	for i := range resp.Pagination.Articles {
-		if err := a.fillArticleEntity(ctx, resp.Pagination.Articles[i], resp.Pagination.Articles[i].UserId); err != nil {
-			a.logger.Error("failed to fill article entity", map[string]any{"user_id": resp.Pagination.Articles[i].UserId, "article_id": resp.Pagination.Articles[i].Id, "error": err.Error()})
-			return nil, err
-		}
-		files, err := a.fileDbStorage.GetPicturesByArticle(ctx, resp.Pagination.Articles[i].Id)
-		if err != nil {
-			a.logger.Error("failed to fetch pictures for article", map[string]any{"article_id": resp.Pagination.Articles[i].Id, "error": err.Error()})
-			return nil, err
-		}
-		for j := range files {
-			fileUrl, err := a.filesStorage.GetFileURL(ctx, files[j].FileName)
-			if err != nil {
-				a.logger.Error("failed to get file URL from MinIO", map[string]any{"file_name": files[j].FileName, "article_id": resp.Pagination.Articles[i].Id, "error": err.Error()})
-				return nil, err
-			}
-			resp.Pagination.Articles[i].Files = append(resp.Pagination.Articles[i].Files, &article_protos.FileEntity{
-				FileName: files[j].FileName,
-				Url:      fileUrl,
-			})
-		}
+	if err := a.fillArticlePage(ctx, req.View, resp.Pagination.Articles); err != nil {
+		return nil, err
 	}
 
 	return resp, nil
@@ -192,16 +159,16 @@ func (a *ArticleService) GetArticlesByUser(ctx context.Context, req *article_pro
 
 func (a *ArticleService) LikeArticle(ctx context.Context, req *article_protos.LikeArticleRequest) (*article_protos.LikeArticleResponse, error) {
 	if liked, err := a.storage.HasUserLikedArticle(ctx, req.UserId, req.ArticleId); err != nil {
-		a.logger.Error("failed to check if user liked article", map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("failed to check if user liked article", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	} else if liked {
 		err := status.Error(codes.AlreadyExists, "you have already liked the post")
-		a.logger.Error("user already liked the post", map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("user already liked the post", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	} else {
 		resp, err := a.storage.LikeArticle(ctx, req)
 		if err != nil {
-			a.logger.Error("failed to like article", map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()})
+			a.logger.Error("failed to like article", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()}))
 			return nil, err
 		}
 		return resp, nil
@@ -209,16 +176,31 @@ func (a *ArticleService) LikeArticle(ctx context.Context, req *article_protos.Li
 }
 
 func (a *ArticleService) RewriteArticle(ctx context.Context, req *article_protos.RewriteArticleRequest) (*article_protos.ArticleEntity, error) {
-	article, err := a.storage.RewriteArticle(ctx, req)
+	original, err := a.storage.GetArticleByID(ctx, &article_protos.GetArticleByIDRequest{ArticleId: req.OriginalArticleId, ViewerId: req.UserId})
 	if err != nil {
-		a.logger.Error("failed to rewrite article", map[string]any{"user_id": req.UserId, "original_article_id": req.OriginalArticleId, "error": err.Error()})
+		a.logger.Error("failed to fetch original article", logging.Fields(ctx, map[string]any{"original_article_id": req.OriginalArticleId, "error": err.Error()}))
+		return nil, err
+	}
+	if original.Article.Status != article_protos.ArticleStatus_ARTICLE_STATUS_PUBLISHED {
+		// a draft is private to its author, answer as if it did not exist
+		return nil, status.Error(codes.NotFound, "original article not found")
+	}
+	attribution, err := a.authorizeRewrite(ctx, req, original.Article)
+	if err != nil {
+		a.logger.Error("rewrite not permitted", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "original_article_id": req.OriginalArticleId, "error": err.Error()}))
+		return nil, err
+	}
+
+	article, err := a.storage.RewriteArticle(ctx, req, attribution)
+	if err != nil {
+		a.logger.Error("failed to rewrite article", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "original_article_id": req.OriginalArticleId, "error": err.Error()}))
 		return nil, err
 	}
 	files := make([]*article_protos.FileEntity, len(req.Files))
 	for i := range req.Files {
 		fileName, url, err := a.filesStorage.CreateFile(ctx, req.Files[i].Name, req.Files[i].Content)
 		if err != nil {
-			a.logger.Error("failed to create file in MinIO", map[string]any{"file_name": req.Files[i].Name, "error": err.Error()})
+			a.logger.Error("failed to create file in MinIO", logging.Fields(ctx, map[string]any{"file_name": req.Files[i].Name, "error": err.Error()}))
 			return nil, err
 		}
 		files[i] = &article_protos.FileEntity{FileName: fileName, Url: url}
@@ -226,7 +208,7 @@ func (a *ArticleService) RewriteArticle(ctx context.Context, req *article_protos
 			FileName:  fileName,
 			ArticleID: article.Id,
 		}); err != nil {
-			a.logger.Error("failed to store picture in database", map[string]any{"file_name": fileName, "article_id": article.Id, "error": err.Error()})
+			a.logger.Error("failed to store picture in database", logging.Fields(ctx, map[string]any{"file_name": fileName, "article_id": article.Id, "error": err.Error()}))
 			return nil, err
 		}
 	}
@@ -235,16 +217,16 @@ func (a *ArticleService) RewriteArticle(ctx context.Context, req *article_protos
 
 func (a *ArticleService) UnlikeArticle(ctx context.Context, req *article_protos.UnlikeArticleRequest) (*article_protos.UnlikeArticleResponse, error) {
 	if liked, err := a.storage.HasUserLikedArticle(ctx, req.UserId, req.ArticleId); err != nil {
-		a.logger.Error("failed to check if user liked article", map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("failed to check if user liked article", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	} else if !liked {
 		err := status.Error(codes.FailedPrecondition, "you have not liked the post")
-		a.logger.Error("user has not liked the post", map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("user has not liked the post", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	} else {
 		resp, err := a.storage.UnlikeArticle(ctx, req)
 		if err != nil {
-			a.logger.Error("failed to unlike article", map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()})
+			a.logger.Error("failed to unlike article", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "error": err.Error()}))
 			return nil, err
 		}
 		return resp, nil
@@ -254,21 +236,197 @@ func (a *ArticleService) UnlikeArticle(ctx context.Context, req *article_protos.
 func (a *ArticleService) UpdateArticle(ctx context.Context, req *article_protos.UpdateArticleRequest) (*article_protos.ArticleEntity, error) {
 	article, err := a.storage.UpdateArticle(ctx, req)
 	if err != nil {
-		a.logger.Error("failed to update article", map[string]any{"article_id": req.ArticleId, "error": err.Error()})
+		a.logger.Error("failed to update article", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
 		return nil, err
 	}
 	return article, nil
 }
 
+func (a *ArticleService) ReconcileLikes(ctx context.Context, req *article_protos.ReconcileLikesRequest) (*article_protos.ReconcileLikesResponse, error) {
+	resp, err := a.storage.ReconcileLikes(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to reconcile likes", logging.Fields(ctx, map[string]any{"batch_size": req.BatchSize, "error": err.Error()}))
+		return nil, err
+	}
+	a.logger.Info("likes reconciled", logging.Fields(ctx, map[string]any{"articles_scanned": resp.ArticlesScanned, "articles_corrected": resp.ArticlesCorrected, "reaction_counts_corrected": resp.ReactionCountsCorrected, "rewrites_counts_corrected": resp.RewritesCountsCorrected}))
+	return resp, nil
+}
+
+func (a *ArticleService) ListArticleLikers(ctx context.Context, req *article_protos.ListArticleLikersRequest) (*article_protos.ListArticleLikersResponse, error) {
+	resp, err := a.storage.ListArticleLikers(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to list article likers", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
+		return nil, err
+	}
+	for i := range resp.Likers {
+		userData, err := a.userService.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: resp.Likers[i].UserId})
+		if err != nil {
+			a.logger.Error("failed to fetch user data", logging.Fields(ctx, map[string]any{"user_id": resp.Likers[i].UserId, "error": err.Error()}))
+			return nil, err
+		}
+		resp.Likers[i].UserFullName = userData.FullName
+		resp.Likers[i].UserUsername = userData.Username
+		resp.Likers[i].UserProfilePic = userData.ProfilePicUrl
+	}
+	return resp, nil
+}
+
+func (a *ArticleService) ListLikedArticles(ctx context.Context, req *article_protos.ListLikedArticlesRequest) (*article_protos.ListLikedArticlesResponse, error) {
+	resp, err := a.storage.ListLikedArticles(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to list liked articles", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "error": err.Error()}))
+		return nil, err
+	}
+	if err := a.fillArticlePage(ctx, article_protos.ArticleView_ARTICLE_VIEW_FULL, resp.Articles); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (a *ArticleService) AddReaction(ctx context.Context, req *article_protos.AddReactionRequest) (*article_protos.ReactionResponse, error) {
+	resp, err := a.storage.AddReaction(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to add reaction", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "reaction_type": req.ReactionType.String(), "error": err.Error()}))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (a *ArticleService) RemoveReaction(ctx context.Context, req *article_protos.RemoveReactionRequest) (*article_protos.ReactionResponse, error) {
+	resp, err := a.storage.RemoveReaction(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to remove reaction", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "article_id": req.ArticleId, "reaction_type": req.ReactionType.String(), "error": err.Error()}))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (a *ArticleService) ListArticleRewrites(ctx context.Context, req *article_protos.ListArticleRewritesRequest) (*article_protos.ListArticleRewritesResponse, error) {
+	resp, err := a.storage.ListArticleRewrites(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to list article rewrites", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
+		return nil, err
+	}
+	if err := a.fillArticleEntities(ctx, resp.Pagination.Articles); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (a *ArticleService) GetArticleAncestry(ctx context.Context, req *article_protos.GetArticleAncestryRequest) (*article_protos.GetArticleAncestryResponse, error) {
+	resp, err := a.storage.GetArticleAncestry(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to fetch article ancestry", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "error": err.Error()}))
+		return nil, err
+	}
+	if err := a.fillArticleEntities(ctx, resp.Ancestors); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (a *ArticleService) GetDerivationTree(ctx context.Context, req *article_protos.GetDerivationTreeRequest) (*article_protos.GetDerivationTreeResponse, error) {
+	resp, err := a.storage.GetDerivationTree(ctx, req)
+	if err != nil {
+		a.logger.Error("failed to fetch derivation tree", logging.Fields(ctx, map[string]any{"article_id": req.ArticleId, "max_depth": req.MaxDepth, "error": err.Error()}))
+		return nil, err
+	}
+
+	var articles []*article_protos.ArticleEntity
+	var collect func(node *article_protos.DerivationNode)
+	collect = func(node *article_protos.DerivationNode) {
+		articles = append(articles, node.Article)
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(resp.Root)
+
+	if err := a.fillArticleEntities(ctx, articles); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// fillArticleEntities enriches many articles, fetching each distinct author only once
+func (a *ArticleService) fillArticleEntities(ctx context.Context, articles []*article_protos.ArticleEntity) error {
+	users := make(map[string]*user_protos.GetUserDataResponse)
+	for _, article := range articles {
+		userData, ok := users[article.UserId]
+		if !ok {
+			var err error
+			userData, err = a.userService.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: article.UserId})
+			if err != nil {
+				a.logger.Error("failed to fetch user data", logging.Fields(ctx, map[string]any{"user_id": article.UserId, "article_id": article.Id, "error": err.Error()}))
+				return err
+			}
+			users[article.UserId] = userData
+		}
+		article.UserFullName = userData.FullName
+		article.UserUsername = userData.Username
+		article.UserProfilePic = userData.ProfilePicUrl
+	}
+	return nil
+}
+
+func (a *ArticleService) fillArticleFiles(ctx context.Context, article *article_protos.ArticleEntity) error {
+	files, err := a.fileDbStorage.GetPicturesByArticle(ctx, article.Id)
+	if err != nil {
+		a.logger.Error("failed to fetch pictures for article", logging.Fields(ctx, map[string]any{"article_id": article.Id, "error": err.Error()}))
+		return err
+	}
+	for i := range files {
+		fileUrl, err := a.filesStorage.GetFileURL(ctx, files[i].FileName)
+		if err != nil {
+			a.logger.Error("failed to get file URL from MinIO", logging.Fields(ctx, map[string]any{"file_name": files[i].FileName, "article_id": article.Id, "error": err.Error()}))
+			return err
+		}
+		article.Files = append(article.Files, &article_protos.FileEntity{
+			FileName: files[i].FileName,
+			Url:      fileUrl,
+		})
+	}
+	return nil
+}
+
 func (a *ArticleService) fillArticleEntity(ctx context.Context, article *article_protos.ArticleEntity, userID string) error {
 	userData, err := a.userService.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: userID})
 	if err != nil {
-		a.logger.Error("failed to fetch user data", map[string]any{"user_id": userID, "error": err.Error()})
+		a.logger.Error("failed to fetch user data", logging.Fields(ctx, map[string]any{"user_id": userID, "error": err.Error()}))
 		return err
 	}
+	setAuthor(article, userData)
+	return nil
+}
 
+// fillArticlePage completes articles read for view. The BASIC view carries the author as user_id
+// only and costs no user service call, the other views look each distinct author up once
+func (a *ArticleService) fillArticlePage(ctx context.Context, view article_protos.ArticleView, articles []*article_protos.ArticleEntity) error {
+	if view == article_protos.ArticleView_ARTICLE_VIEW_BASIC {
+		return nil
+	}
+	authors := make(map[string]*user_protos.GetUserDataResponse)
+	for _, article := range articles {
+		userData, ok := authors[article.UserId]
+		if !ok {
+			var err error
+			userData, err = a.userService.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: article.UserId})
+			if err != nil {
+				a.logger.Error("failed to fetch user data", logging.Fields(ctx, map[string]any{"user_id": article.UserId, "article_id": article.Id, "error": err.Error()}))
+				return err
+			}
+			authors[article.UserId] = userData
+		}
+		setAuthor(article, userData)
+		if err := a.fillArticleFiles(ctx, article); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setAuthor(article *article_protos.ArticleEntity, userData *user_protos.GetUserDataResponse) {
 	article.UserFullName = userData.FullName
 	article.UserUsername = userData.Username
 	article.UserProfilePic = userData.ProfilePicUrl
-	return nil
 }
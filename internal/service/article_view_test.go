@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+)
+
+// pageArticles returns the same page of three articles by two authors for every read
+type pageArticles struct {
+	repos.ArticleRepo
+}
+
+func (pageArticles) GetArticles(context.Context, *article_protos.GetArticlesRequest) (*article_protos.GetArticlesResponse, error) {
+	return &article_protos.GetArticlesResponse{Pagination: &article_protos.PaginationResponse{Articles: []*article_protos.ArticleEntity{
+		{Id: "a-1", UserId: "u-1"}, {Id: "a-2", UserId: "u-2"}, {Id: "a-3", UserId: "u-1"},
+	}}}, nil
+}
+
+type countingUsers struct {
+	user_protos.UserServiceClient
+	calls int
+}
+
+func (u *countingUsers) GetUserData(_ context.Context, in *user_protos.GetUserDataRequest, _ ...grpc.CallOption) (*user_protos.GetUserDataResponse, error) {
+	u.calls++
+	return &user_protos.GetUserDataResponse{Username: "name-of-" + in.UserId}, nil
+}
+
+type noPictures struct {
+	repos.PictureRepo
+}
+
+func (noPictures) GetPicturesByArticle(context.Context, string) ([]*models.Picture, error) {
+	return nil, nil
+}
+
+func TestGetArticlesLooksUpAuthorsPerView(t *testing.T) {
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		view  article_protos.ArticleView
+		calls int
+	}{
+		{article_protos.ArticleView_ARTICLE_VIEW_BASIC, 0},
+		{article_protos.ArticleView_ARTICLE_VIEW_FULL, 2},
+	} {
+		users := &countingUsers{}
+		svc := NewArticleService(pageArticles{}, log, nil, users, noPictures{})
+		resp, err := svc.GetArticles(context.Background(), &article_protos.GetArticlesRequest{
+			View:       tc.view,
+			Pagination: &article_protos.PaginationRequest{Page: 1, PageSize: 3},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.view, err)
+		}
+		if users.calls != tc.calls {
+			t.Errorf("%s: %d user service calls, want %d", tc.view, users.calls, tc.calls)
+		}
+		if tc.calls > 0 && resp.Pagination.Articles[2].UserUsername != "name-of-u-1" {
+			t.Errorf("%s: author of a repeated user not filled: %+v", tc.view, resp.Pagination.Articles[2])
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const filesGCBatchSize = 500
+
+type (
+	// FilesGC removes files left behind by deleted articles and interrupted uploads
+	FilesGC struct {
+		pictures repos.PictureRepo
+		files    repos.MinIOStorage
+		logger   *logger.Logger
+	}
+
+	// FilesGCReport summarises a garbage collection pass, in a dry run the counts are what would be deleted
+	FilesGCReport struct {
+		OrphanPictures int   `json:"orphan_pictures"`
+		ObjectsScanned int   `json:"objects_scanned"`
+		OrphanObjects  int   `json:"orphan_objects"`
+		BytesReclaimed int64 `json:"bytes_reclaimed"`
+		Failed         int   `json:"failed"`
+		DryRun         bool  `json:"dry_run"`
+	}
+)
+
+func NewFilesGC(pictures repos.PictureRepo, files repos.MinIOStorage, logger *logger.Logger) *FilesGC {
+	return &FilesGC{
+		pictures: pictures,
+		files:    files,
+		logger:   logger,
+	}
+}
+
+// Run deletes pictures whose article is gone together with their objects, then objects no picture
+// references. Unreferenced objects younger than minAge are kept since they may belong to an upload
+// whose picture row is not written yet
+func (g *FilesGC) Run(ctx context.Context, minAge time.Duration, dryRun bool) (*FilesGCReport, error) {
+	report := &FilesGCReport{DryRun: dryRun}
+
+	orphans, err := g.pictures.ListOrphanPictures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, picture := range orphans {
+		report.OrphanPictures++
+		if dryRun {
+			continue
+		}
+		if err := g.files.DeleteFile(ctx, picture.FileName); err != nil {
+			report.Failed++
+			g.logger.Error("failed to delete file from MinIO", map[string]any{"file_name": picture.FileName, "error": err.Error()})
+			continue
+		}
+		if err := g.pictures.DeletePicture(ctx, picture.FileName, picture.ArticleID); err != nil {
+			report.Failed++
+			g.logger.Error("failed to delete picture from database", map[string]any{"file_name": picture.FileName, "article_id": picture.ArticleID, "error": err.Error()})
+		}
+	}
+
+	cutoff := time.Now().Add(-minAge)
+	batch := make([]models.StoredFile, 0, filesGCBatchSize)
+	sweep := func() error {
+		names := make([]string, len(batch))
+		for i := range batch {
+			names[i] = batch[i].Name
+		}
+		referenced, err := g.pictures.ReferencedFiles(ctx, names)
+		if err != nil {
+			return err
+		}
+		for _, file := range batch {
+			if referenced[file.Name] || file.LastModified.After(cutoff) {
+				continue
+			}
+			report.OrphanObjects++
+			report.BytesReclaimed += file.Size
+			if dryRun {
+				continue
+			}
+			if err := g.files.DeleteFile(ctx, file.Name); err != nil {
+				report.Failed++
+				report.BytesReclaimed -= file.Size
+				g.logger.Error("failed to delete file from MinIO", map[string]any{"file_name": file.Name, "error": err.Error()})
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err = g.files.WalkFiles(ctx, func(file models.StoredFile) error {
+		report.ObjectsScanned++
+		batch = append(batch, file)
+		if len(batch) < filesGCBatchSize {
+			return nil
+		}
+		return sweep()
+	})
+	if err == nil && len(batch) > 0 {
+		err = sweep()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g.logger.Info("files garbage collection finished", map[string]any{"orphan_pictures": report.OrphanPictures, "orphan_objects": report.OrphanObjects, "bytes_reclaimed": report.BytesReclaimed, "failed": report.Failed, "dry_run": dryRun})
+	return report, nil
+}
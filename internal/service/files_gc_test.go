@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type fakePictures struct {
+	pictures []*models.Picture
+	orphans  map[string]bool // article ids that no longer exist
+	deleted  []string
+}
+
+func (f *fakePictures) CreatePicture(context.Context, *models.Picture) error { return nil }
+
+func (f *fakePictures) GetPicture(context.Context, string, string) (*models.Picture, error) {
+	return nil, nil
+}
+
+func (f *fakePictures) GetPicturesByArticle(context.Context, string) ([]*models.Picture, error) {
+	return nil, nil
+}
+
+func (f *fakePictures) DeletePicture(_ context.Context, fileName, _ string) error {
+	f.deleted = append(f.deleted, fileName)
+	return nil
+}
+
+func (f *fakePictures) ListOrphanPictures(context.Context) ([]*models.Picture, error) {
+	var orphans []*models.Picture
+	for _, p := range f.pictures {
+		if f.orphans[p.ArticleID] {
+			orphans = append(orphans, p)
+		}
+	}
+	return orphans, nil
+}
+
+func (f *fakePictures) ReferencedFiles(_ context.Context, names []string) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	for _, p := range f.pictures {
+		for _, name := range names {
+			if p.FileName == name {
+				referenced[name] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+func (f *fakePictures) PublishedFiles(context.Context, []string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+type fakeFiles struct {
+	objects []models.StoredFile
+	deleted []string
+}
+
+func (f *fakeFiles) CreateFile(context.Context, string, []byte) (string, string, error) {
+	return "", "", nil
+}
+
+func (f *fakeFiles) DeleteFile(_ context.Context, name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func (f *fakeFiles) GetFileURL(context.Context, string) (string, error) { return "", nil }
+
+func (f *fakeFiles) PublicURL(name string) string { return "http://files/" + name }
+
+func (f *fakeFiles) OpenFile(context.Context, string) (io.ReadCloser, models.StoredFile, error) {
+	return nil, models.StoredFile{}, fs.ErrNotExist
+}
+
+func (f *fakeFiles) PutFile(context.Context, string, io.Reader, int64) error { return nil }
+
+func (f *fakeFiles) WalkFiles(_ context.Context, fn func(models.StoredFile) error) error {
+	for _, o := range f.objects {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestGC(t *testing.T) (*FilesGC, *fakePictures, *fakeFiles) {
+	t.Helper()
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	pictures := &fakePictures{
+		pictures: []*models.Picture{
+			{FileName: "live.png", ArticleID: "a-1"},
+			{FileName: "deleted.png", ArticleID: "a-gone"},
+		},
+		orphans: map[string]bool{"a-gone": true},
+	}
+	files := &fakeFiles{objects: []models.StoredFile{
+		{Name: "live.png", Size: 10, LastModified: old},
+		{Name: "deleted.png", Size: 20, LastModified: old},
+		{Name: "stray.png", Size: 30, LastModified: old},
+		{Name: "uploading.png", Size: 40, LastModified: time.Now()},
+	}}
+	return NewFilesGC(pictures, files, log), pictures, files
+}
+
+func TestFilesGCRun(t *testing.T) {
+	gc, pictures, files := newTestGC(t)
+
+	report, err := gc.Run(context.Background(), 24*time.Hour, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OrphanPictures != 1 || report.OrphanObjects != 1 || report.BytesReclaimed != 30 || report.ObjectsScanned != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(pictures.deleted) != 1 || pictures.deleted[0] != "deleted.png" {
+		t.Fatalf("deleted pictures = %v", pictures.deleted)
+	}
+	if len(files.deleted) != 2 || files.deleted[0] != "deleted.png" || files.deleted[1] != "stray.png" {
+		t.Fatalf("deleted objects = %v, want deleted.png and stray.png", files.deleted)
+	}
+}
+
+func TestFilesGCDryRun(t *testing.T) {
+	gc, pictures, files := newTestGC(t)
+
+	report, err := gc.Run(context.Background(), 24*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.OrphanPictures != 1 || report.OrphanObjects != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(pictures.deleted) != 0 || len(files.deleted) != 0 {
+		t.Fatalf("dry run deleted pictures %v and objects %v", pictures.deleted, files.deleted)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type (
+	// LikesReconciler periodically recomputes denormalised likes counters
+	LikesReconciler struct {
+		storage   repos.ArticleRepo
+		logger    *logger.Logger
+		interval  time.Duration
+		batchSize int32
+		cancel    context.CancelFunc
+		done      chan struct{}
+	}
+)
+
+func NewLikesReconciler(storage repos.ArticleRepo, logger *logger.Logger, cfg *config.Config) *LikesReconciler {
+	return &LikesReconciler{
+		storage:   storage,
+		logger:    logger,
+		interval:  time.Duration(cfg.Reconcile.Interval) * time.Second,
+		batchSize: int32(cfg.Reconcile.BatchSize),
+	}
+}
+
+// Start launches the background loop, it is a no-op when the interval is not positive
+func (r *LikesReconciler) Start() {
+	if r.interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.run(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels a running reconciliation and waits for the loop to exit
+func (r *LikesReconciler) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+func (r *LikesReconciler) run(ctx context.Context) {
+	resp, err := r.storage.ReconcileLikes(ctx, &article_protos.ReconcileLikesRequest{BatchSize: r.batchSize})
+	if err != nil {
+		r.logger.Error("failed to reconcile likes", map[string]any{"error": err.Error()})
+		return
+	}
+	r.logger.Info("likes reconciliation finished", map[string]any{"articles_scanned": resp.ArticlesScanned, "articles_corrected": resp.ArticlesCorrected, "reaction_counts_corrected": resp.ReactionCountsCorrected, "rewrites_counts_corrected": resp.RewritesCountsCorrected})
+}
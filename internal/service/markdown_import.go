@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/mdbundle"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ImportMarkdownBundle turns every Markdown file of the uploaded zip archives into a draft. Files are
+// reported on one by one, a file that fails does not stop the others
+func (a *ArticleService) ImportMarkdownBundle(ctx context.Context, req *article_protos.ImportMarkdownBundleRequest) (*article_protos.ImportMarkdownBundleResponse, error) {
+	if req.UserId == "" || len(req.Files) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id and at least one bundle are required")
+	}
+
+	resp := &article_protos.ImportMarkdownBundleResponse{}
+	var created []*article_protos.ArticleEntity
+	for _, file := range req.Files {
+		bundle, err := mdbundle.Open(file.Content)
+		if err != nil {
+			resp.Results = append(resp.Results, &article_protos.MarkdownImportResult{Bundle: file.Name, Error: err.Error()})
+			resp.Failed++
+			continue
+		}
+		for _, doc := range bundle.Documents {
+			result := a.importMarkdownDocument(ctx, req.UserId, bundle, doc)
+			result.Bundle = file.Name
+			if result.Error != "" {
+				a.logger.Error("failed to import markdown document", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "bundle": file.Name, "path": doc.Path, "error": result.Error}))
+				resp.Failed++
+			} else {
+				created = append(created, result.Article)
+				resp.Imported++
+			}
+			resp.Results = append(resp.Results, result)
+		}
+	}
+
+	if err := a.fillArticleEntities(ctx, created); err != nil {
+		return nil, err
+	}
+	a.logger.Info("markdown bundle imported", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "imported": resp.Imported, "failed": resp.Failed}))
+	return resp, nil
+}
+
+// importMarkdownDocument uploads the images of doc, points its links at the stored objects and
+// creates the draft together with its pictures. Images uploaded before a failure are left to gc-files
+func (a *ArticleService) importMarkdownDocument(ctx context.Context, userID string, bundle *mdbundle.Bundle, doc *mdbundle.Document) *article_protos.MarkdownImportResult {
+	result := &article_protos.MarkdownImportResult{Path: doc.Path, Warnings: doc.Warnings}
+	if doc.Err != nil {
+		result.Error = doc.Err.Error()
+		return result
+	}
+
+	tags := models.NormalizeTags(doc.Tags)
+	if len(tags) > models.MaxTags {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("only the first %d of %d tags were kept", models.MaxTags, len(tags)))
+		tags = tags[:models.MaxTags]
+	}
+
+	images, warnings := bundle.Images(doc)
+	result.Warnings = append(result.Warnings, warnings...)
+	urls := make(map[string]string, len(images))
+	uploaded := make(map[string]string, len(images))
+	for _, image := range images {
+		if fileName, ok := uploaded[image.Path]; ok {
+			urls[image.Ref] = a.filesStorage.PublicURL(fileName)
+			continue
+		}
+		data, err := bundle.ReadFile(image.Path)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		fileName, _, err := a.filesStorage.CreateFile(ctx, path.Base(image.Path), data)
+		if err != nil {
+			a.logger.Error("failed to create file in MinIO", logging.Fields(ctx, map[string]any{"file_name": image.Path, "error": err.Error()}))
+			result.Error = fmt.Sprintf("failed to store image %s", image.Path)
+			return result
+		}
+		uploaded[image.Path] = fileName
+		urls[image.Ref] = a.filesStorage.PublicURL(fileName)
+		result.Images = append(result.Images, fileName)
+	}
+	body := mdbundle.RewriteImages(doc.Body, func(ref string) (string, bool) {
+		url, ok := urls[ref]
+		return url, ok
+	})
+
+	article, err := a.storage.CreateImportedArticle(ctx, &article_protos.CreateArticleRequest{
+		UserId:        userID,
+		Title:         doc.Title,
+		Content:       body,
+		ContentFormat: article_protos.ContentFormat_CONTENT_FORMAT_MARKDOWN,
+		Status:        article_protos.ArticleStatus_ARTICLE_STATUS_DRAFT,
+		Tags:          tags,
+	}, doc.Date, result.Images)
+	if err != nil {
+		result.Error = status.Convert(err).Message()
+		return result
+	}
+	result.Article = article
+	return result
+}
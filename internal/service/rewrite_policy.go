@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// authorizeRewrite enforces the original author's rewrite policy and license terms.
+// It may set req.License for share-alike originals and returns the attribution the rewrite must carry.
+func (a *ArticleService) authorizeRewrite(ctx context.Context, req *article_protos.RewriteArticleRequest, original *article_protos.ArticleEntity) (string, error) {
+	// authors keep full control over rewrites of their own work
+	if req.UserId == original.UserId {
+		return "", nil
+	}
+
+	switch original.RewritePolicy {
+	case article_protos.RewritePolicy_REWRITE_POLICY_DISALLOWED:
+		return "", status.Error(codes.PermissionDenied, "the author does not allow rewrites of this article")
+	case article_protos.RewritePolicy_REWRITE_POLICY_FOLLOWERS_ONLY:
+		resp, err := a.userService.IsFollowing(ctx, &user_protos.IsFollowingRequest{
+			FollowerId: req.UserId,
+			FolloweeId: original.UserId,
+		})
+		if err != nil {
+			a.logger.Error("failed to check follower status", logging.Fields(ctx, map[string]any{"user_id": req.UserId, "author_id": original.UserId, "error": err.Error()}))
+			return "", status.Error(codes.Unavailable, "could not verify follower status")
+		}
+		if !resp.Following {
+			return "", status.Error(codes.PermissionDenied, "only followers of the author may rewrite this article")
+		}
+	}
+
+	license, _ := models.LicenseFromProto(original.License)
+	if models.LicenseIsShareAlike(license) {
+		switch req.License {
+		case article_protos.License_LICENSE_UNSPECIFIED:
+			req.License = original.License
+		case original.License:
+		default:
+			return "", status.Errorf(codes.FailedPrecondition, "rewrites of this article must be licensed under %s", models.LicenseName(license))
+		}
+	}
+	if !models.LicenseRequiresAttribution(license) {
+		return "", nil
+	}
+
+	author, err := a.userService.GetUserData(ctx, &user_protos.GetUserDataRequest{UserId: original.UserId})
+	if err != nil {
+		a.logger.Error("failed to fetch original author", logging.Fields(ctx, map[string]any{"user_id": original.UserId, "error": err.Error()}))
+		return "", err
+	}
+	return fmt.Sprintf("Based on %q by %s (@%s), licensed under %s", original.Title, author.FullName, author.Username, models.LicenseName(license)), nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/user_protos"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeFollows answers IsFollowing from a set of "follower>followee" pairs
+type fakeFollows struct {
+	user_protos.UserServiceClient
+	follows map[string]bool
+	err     error
+}
+
+func (f fakeFollows) IsFollowing(_ context.Context, in *user_protos.IsFollowingRequest, _ ...grpc.CallOption) (*user_protos.IsFollowingResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &user_protos.IsFollowingResponse{Following: f.follows[in.FollowerId+">"+in.FolloweeId]}, nil
+}
+
+func TestAuthorizeRewriteFollowersOnly(t *testing.T) {
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := &article_protos.ArticleEntity{
+		UserId:        "author",
+		RewritePolicy: article_protos.RewritePolicy_REWRITE_POLICY_FOLLOWERS_ONLY,
+		License:       article_protos.License_LICENSE_ALL_RIGHTS_RESERVED,
+	}
+	follows := fakeFollows{follows: map[string]bool{"fan>author": true}}
+
+	for _, tc := range []struct {
+		user  string
+		users user_protos.UserServiceClient
+		want  codes.Code
+	}{
+		{"fan", follows, codes.OK},
+		{"stranger", follows, codes.PermissionDenied},
+		{"author", fakeFollows{err: errors.New("must not be asked")}, codes.OK},
+		{"fan", fakeFollows{err: status.Error(codes.Unavailable, "down")}, codes.Unavailable},
+	} {
+		a := &ArticleService{userService: tc.users, logger: log}
+		_, err := a.authorizeRewrite(context.Background(), &article_protos.RewriteArticleRequest{UserId: tc.user}, original)
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.user, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/internal/site"
+	"github.com/ruziba3vich/mm_article_service/internal/sitemap"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type (
+	// SitemapGenerator keeps the stored sitemap files in step with the published articles
+	SitemapGenerator struct {
+		store    repos.SitemapStore
+		links    site.Links
+		pageSize int
+		interval time.Duration
+		logger   *logger.Logger
+		cancel   context.CancelFunc
+		done     chan struct{}
+	}
+
+	// SitemapReport summarises a regeneration, Skipped is set when nothing changed since the last one
+	SitemapReport struct {
+		Articles     int64 `json:"articles"`
+		Pages        int   `json:"pages"`
+		PagesWritten int   `json:"pages_written"`
+		PagesDeleted int64 `json:"pages_deleted"`
+		Skipped      bool  `json:"skipped"`
+	}
+)
+
+func NewSitemapGenerator(store repos.SitemapStore, cfg *config.Config, logger *logger.Logger) *SitemapGenerator {
+	pageSize := cfg.Sitemap.PageSize
+	if pageSize <= 0 || pageSize > sitemap.MaxURLs {
+		pageSize = sitemap.MaxURLs
+	}
+	return &SitemapGenerator{
+		store:    store,
+		links:    site.NewLinks(cfg),
+		pageSize: pageSize,
+		interval: time.Duration(cfg.Sitemap.Interval) * time.Second,
+		logger:   logger,
+	}
+}
+
+// Start regenerates the sitemaps right away and then on every tick, it is a no-op when the
+// interval is not positive. The first run walks every page since a changed site address is only
+// picked up on restart
+func (g *SitemapGenerator) Start() {
+	if g.interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	g.done = make(chan struct{})
+
+	go func() {
+		defer close(g.done)
+		g.run(ctx, true)
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.run(ctx, false)
+			}
+		}
+	}()
+}
+
+// Stop cancels a running regeneration and waits for the loop to exit
+func (g *SitemapGenerator) Stop() {
+	if g.cancel == nil {
+		return
+	}
+	g.cancel()
+	<-g.done
+}
+
+func (g *SitemapGenerator) run(ctx context.Context, force bool) {
+	report, err := g.Run(ctx, force)
+	if err != nil {
+		g.logger.Error("failed to regenerate sitemaps", map[string]any{"error": err.Error()})
+		return
+	}
+	if !report.Skipped {
+		g.logger.Info("sitemaps regenerated", map[string]any{"articles": report.Articles, "pages": report.Pages, "pages_written": report.PagesWritten, "pages_deleted": report.PagesDeleted})
+	}
+}
+
+// Run brings the stored sitemap files up to date. Pages are cut from the published articles in
+// publication order, so new articles only reach the last page, and a page is rendered and written
+// only when its URLs or their lastmod changed. Unless force is set the walk is skipped altogether
+// when neither the number of published articles nor their latest edit moved since the last run
+func (g *SitemapGenerator) Run(ctx context.Context, force bool) (*SitemapReport, error) {
+	count, lastEdit, err := g.store.PublishedSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := g.store.SitemapPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &SitemapReport{Articles: count}
+	if !force && upToDate(stored, count, lastEdit) {
+		report.Pages, report.Skipped = len(stored), true
+		return report, nil
+	}
+
+	fingerprints := make(map[int]string, len(stored))
+	for _, p := range stored {
+		fingerprints[p.Page] = p.Fingerprint
+	}
+
+	var afterCreated time.Time
+	var afterID string
+	for {
+		entries, err := g.store.SitemapEntries(ctx, afterCreated, afterID, g.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		report.Pages++
+		last := entries[len(entries)-1]
+		afterCreated, afterID = last.CreatedAt, last.ID
+
+		written, err := g.writePage(ctx, report.Pages, entries, fingerprints[report.Pages])
+		if err != nil {
+			return nil, err
+		}
+		if written {
+			report.PagesWritten++
+		}
+		if len(entries) < g.pageSize {
+			break
+		}
+	}
+
+	if report.PagesDeleted, err = g.store.DeleteSitemapPages(ctx, report.Pages+1); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// writePage renders and stores page unless its fingerprint matches the stored one
+func (g *SitemapGenerator) writePage(ctx context.Context, page int, entries []models.SitemapEntry, storedFingerprint string) (bool, error) {
+	urls := make([]sitemap.URL, len(entries))
+	var modified time.Time
+	for i, e := range entries {
+		urls[i] = sitemap.URL{Loc: g.links.Article(e.Slug), LastMod: e.EditedAt}
+		if e.EditedAt.After(modified) {
+			modified = e.EditedAt
+		}
+	}
+	fingerprint := sitemap.Fingerprint(urls)
+	if fingerprint == storedFingerprint {
+		return false, nil
+	}
+	body, err := sitemap.URLSet(urls)
+	if err != nil {
+		return false, err
+	}
+	return true, g.store.SaveSitemapPage(ctx, &models.SitemapPage{
+		Page:         page,
+		URLCount:     len(urls),
+		Fingerprint:  fingerprint,
+		LastModified: modified,
+		Body:         body,
+		GeneratedAt:  time.Now(),
+	})
+}
+
+// upToDate reports whether the stored pages were built from count articles whose latest edit is
+// lastEdit. Any publish or edit moves the latest edit and any removal lowers the count
+func upToDate(stored []models.SitemapPage, count int64, lastEdit time.Time) bool {
+	var urls int64
+	var modified time.Time
+	for _, p := range stored {
+		urls += int64(p.URLCount)
+		if p.LastModified.After(modified) {
+			modified = p.LastModified
+		}
+	}
+	return urls == count && modified.Equal(lastEdit)
+}
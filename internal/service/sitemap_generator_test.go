@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type memorySitemapStore struct {
+	articles []models.SitemapEntry // published, in (created_at, id) order
+	pages    map[int]models.SitemapPage
+	saved    []int
+}
+
+func (s *memorySitemapStore) PublishedSummary(context.Context) (int64, time.Time, error) {
+	var last time.Time
+	for _, a := range s.articles {
+		if a.EditedAt.After(last) {
+			last = a.EditedAt
+		}
+	}
+	return int64(len(s.articles)), last, nil
+}
+
+func (s *memorySitemapStore) SitemapEntries(_ context.Context, afterCreated time.Time, afterID string, limit int) ([]models.SitemapEntry, error) {
+	var out []models.SitemapEntry
+	for _, a := range s.articles {
+		if afterID != "" && (a.CreatedAt.Before(afterCreated) || (a.CreatedAt.Equal(afterCreated) && a.ID <= afterID)) {
+			continue
+		}
+		if out = append(out, a); len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (s *memorySitemapStore) SitemapPages(context.Context) ([]models.SitemapPage, error) {
+	var pages []models.SitemapPage
+	for _, p := range s.pages {
+		p.Body = nil
+		pages = append(pages, p)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Page < pages[j].Page })
+	return pages, nil
+}
+
+func (s *memorySitemapStore) SitemapPage(_ context.Context, page int) (*models.SitemapPage, error) {
+	p := s.pages[page]
+	return &p, nil
+}
+
+func (s *memorySitemapStore) SaveSitemapPage(_ context.Context, page *models.SitemapPage) error {
+	s.pages[page.Page] = *page
+	s.saved = append(s.saved, page.Page)
+	return nil
+}
+
+func (s *memorySitemapStore) DeleteSitemapPages(_ context.Context, from int) (int64, error) {
+	var deleted int64
+	for page := range s.pages {
+		if page >= from {
+			delete(s.pages, page)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestSitemapGeneratorIsIncremental(t *testing.T) {
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memorySitemapStore{pages: map[int]models.SitemapPage{}}
+	for i := 0; i < 5; i++ {
+		created := start.Add(time.Duration(i) * time.Hour)
+		store.articles = append(store.articles, models.SitemapEntry{ID: fmt.Sprintf("a-%d", i), Slug: fmt.Sprintf("article-%d", i), CreatedAt: created, EditedAt: created})
+	}
+	cfg := &config.Config{
+		Site:    &config.SiteConfig{BaseURL: "https://example.com"},
+		Sitemap: &config.SitemapConfig{PageSize: 2},
+	}
+	g := NewSitemapGenerator(store, cfg, log)
+	ctx := context.Background()
+
+	report, err := g.Run(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pages != 3 || report.PagesWritten != 3 || len(store.pages) != 3 {
+		t.Fatalf("first run: %+v", report)
+	}
+	if body := string(store.pages[3].Body); !strings.Contains(body, "<loc>https://example.com/articles/article-4</loc>") {
+		t.Errorf("last page misses article-4:\n%s", body)
+	}
+
+	if report, _ = g.Run(ctx, false); !report.Skipped {
+		t.Errorf("unchanged articles should skip the walk: %+v", report)
+	}
+
+	// an edit rewrites only the page listing the article
+	store.saved = nil
+	store.articles[2].EditedAt = start.Add(48 * time.Hour)
+	if report, _ = g.Run(ctx, false); report.PagesWritten != 1 || len(store.saved) != 1 || store.saved[0] != 2 {
+		t.Errorf("edit: %+v, saved %v", report, store.saved)
+	}
+
+	// unpublishing shrinks the sitemap, the trailing page goes away
+	store.articles = store.articles[:4]
+	if report, _ = g.Run(ctx, false); report.Pages != 2 || report.PagesDeleted != 1 || len(store.pages) != 2 {
+		t.Errorf("removal: %+v", report)
+	}
+
+	store.articles = nil
+	if report, _ = g.Run(ctx, false); report.Pages != 0 || len(store.pages) != 0 {
+		t.Errorf("no articles: %+v", report)
+	}
+}
@@ -0,0 +1,63 @@
+// Package site builds the public website URLs handed out in feeds and sitemaps, and the addresses
+// of the feeds this service serves itself
+package site
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+)
+
+// Links builds absolute URLs below the configured site and API addresses. Neither comes from a
+// request, so a link stays the same whoever asked and whatever Host they sent
+type Links struct {
+	Name string
+	base string
+	api  string
+}
+
+func NewLinks(cfg *config.Config) Links {
+	return Links{Name: cfg.Site.Name, base: cfg.Site.BaseURL, api: cfg.APIBaseURL}
+}
+
+// Home is the site's front page
+func (l Links) Home() string {
+	return l.base + "/"
+}
+
+// Article is the page of an article, addressed by its current slug
+func (l Links) Article(slug string) string {
+	return l.base + "/articles/" + url.PathEscape(slug)
+}
+
+// Author is the profile page listing an author's articles
+func (l Links) Author(userID string) string {
+	return l.base + "/users/" + url.PathEscape(userID)
+}
+
+// Tag is the page listing the articles of a tag
+func (l Links) Tag(tag string) string {
+	return l.base + "/tags/" + url.PathEscape(tag)
+}
+
+// SitemapIndex is the sitemap index, the site is expected to proxy it and the sitemap files to
+// this service since search engines only accept sitemaps for URLs on their own host
+func (l Links) SitemapIndex() string {
+	return l.base + "/sitemap.xml"
+}
+
+// Sitemap is one numbered sitemap file, numbering starts at 1
+func (l Links) Sitemap(page int) string {
+	return l.base + "/sitemaps/" + strconv.Itoa(page) + ".xml"
+}
+
+// AuthorFeed is the address of an author's feed in format, rss or atom
+func (l Links) AuthorFeed(userID, format string) string {
+	return l.api + "/feeds/authors/" + url.PathEscape(userID) + "/" + format
+}
+
+// TagFeed is the address of a tag's feed in format, rss or atom
+func (l Links) TagFeed(tag, format string) string {
+	return l.api + "/feeds/tags/" + url.PathEscape(tag) + "/" + format
+}
@@ -0,0 +1,99 @@
+package sitemap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/logging"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/internal/site"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Handler serves the sitemap index and the files the background job stored, it never renders
+// article pages itself
+type Handler struct {
+	store  repos.SitemapStore
+	links  site.Links
+	maxAge int
+	logger *logger.Logger
+}
+
+func NewHandler(cfg *config.Config, store repos.SitemapStore, logger *logger.Logger) *Handler {
+	return &Handler{
+		store:  store,
+		links:  site.NewLinks(cfg),
+		maxAge: cfg.Sitemap.Interval,
+		logger: logger,
+	}
+}
+
+// Register adds the sitemap routes to mux, GET patterns answer HEAD requests too
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /sitemap.xml", h.index)
+	mux.HandleFunc("GET /sitemaps/{file}", h.page)
+}
+
+func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
+	pages, err := h.store.SitemapPages(r.Context())
+	if err != nil {
+		h.writeError(w, r, "index", err)
+		return
+	}
+	sitemaps := make([]URL, len(pages))
+	var modified time.Time
+	for i, p := range pages {
+		sitemaps[i] = URL{Loc: h.links.Sitemap(p.Page), LastMod: p.LastModified}
+		if p.GeneratedAt.After(modified) {
+			modified = p.GeneratedAt
+		}
+	}
+	body, err := Index(sitemaps)
+	if err != nil {
+		h.writeError(w, r, "index", err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	h.write(w, r, `"`+hex.EncodeToString(sum[:16])+`"`, modified, body)
+}
+
+func (h *Handler) page(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".xml")
+	n, err := strconv.Atoi(name)
+	if !ok || err != nil || n < 1 || strconv.Itoa(n) != name {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := h.store.SitemapPage(r.Context(), n)
+	if err != nil {
+		h.writeError(w, r, name, err)
+		return
+	}
+	h.write(w, r, `"`+p.Fingerprint[:32]+`"`, p.GeneratedAt, p.Body)
+}
+
+// write lets http.ServeContent answer If-None-Match, If-Modified-Since and HEAD
+func (h *Handler) write(w http.ResponseWriter, r *http.Request, etag string, modified time.Time, body []byte) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", h.maxAge))
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, file string, err error) {
+	if status.Code(err) == codes.NotFound {
+		http.NotFound(w, r)
+		return
+	}
+	h.logger.Error("failed to serve sitemap", logging.Fields(r.Context(), map[string]any{"file": file, "error": err.Error()}))
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,93 @@
+// Package sitemap renders and serves the sitemaps.org files that point search engines at articles
+package sitemap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"time"
+)
+
+const (
+	ContentType = "application/xml; charset=utf-8"
+
+	// MaxURLs is the most URLs the protocol allows in one sitemap file
+	MaxURLs = 50_000
+
+	namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+type (
+	// URL is a page, or in the index a sitemap file, with the time it last changed
+	URL struct {
+		Loc     string
+		LastMod time.Time
+	}
+
+	urlSet struct {
+		XMLName xml.Name     `xml:"urlset"`
+		NS      string       `xml:"xmlns,attr"`
+		URLs    []xmlElement `xml:"url"`
+	}
+
+	sitemapIndex struct {
+		XMLName  xml.Name     `xml:"sitemapindex"`
+		NS       string       `xml:"xmlns,attr"`
+		Sitemaps []xmlElement `xml:"sitemap"`
+	}
+
+	xmlElement struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod,omitempty"`
+	}
+)
+
+// URLSet renders a sitemap file listing urls
+func URLSet(urls []URL) ([]byte, error) {
+	return marshal(urlSet{NS: namespace, URLs: elements(urls)})
+}
+
+// Index renders a sitemap index listing the sitemap files
+func Index(sitemaps []URL) ([]byte, error) {
+	return marshal(sitemapIndex{NS: namespace, Sitemaps: elements(sitemaps)})
+}
+
+// Fingerprint identifies the rendered contents of urls, a page whose fingerprint is unchanged
+// does not need to be rendered again
+func Fingerprint(urls []URL) string {
+	h := sha256.New()
+	for _, u := range urls {
+		h.Write([]byte(u.Loc))
+		h.Write([]byte{0})
+		h.Write([]byte(lastMod(u.LastMod)))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func elements(urls []URL) []xmlElement {
+	out := make([]xmlElement, len(urls))
+	for i, u := range urls {
+		out[i] = xmlElement{Loc: u.Loc, LastMod: lastMod(u.LastMod)}
+	}
+	return out
+}
+
+// lastMod formats t as a W3C datetime, second precision is all search engines use
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshal(doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
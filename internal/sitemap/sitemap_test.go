@@ -0,0 +1,125 @@
+package sitemap
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var edited = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type fakeStore struct {
+	repos.SitemapStore
+	pages []models.SitemapPage
+}
+
+func (f *fakeStore) SitemapPages(context.Context) ([]models.SitemapPage, error) {
+	return f.pages, nil
+}
+
+func (f *fakeStore) SitemapPage(_ context.Context, page int) (*models.SitemapPage, error) {
+	for i := range f.pages {
+		if f.pages[i].Page == page {
+			return &f.pages[i], nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "sitemap page not found")
+}
+
+func TestURLSet(t *testing.T) {
+	out, err := URLSet([]URL{{Loc: "https://example.com/articles/a?x=1&y=2", LastMod: edited}, {Loc: "https://example.com/articles/b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName xml.Name
+		URLs    []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if doc.XMLName.Space != namespace || doc.XMLName.Local != "urlset" {
+		t.Errorf("unexpected root %v", doc.XMLName)
+	}
+	if len(doc.URLs) != 2 || doc.URLs[0].Loc != "https://example.com/articles/a?x=1&y=2" || doc.URLs[0].LastMod != "2024-03-01T12:00:00Z" {
+		t.Errorf("unexpected urls %+v", doc.URLs)
+	}
+	if strings.Contains(string(out), "<lastmod></lastmod>") {
+		t.Errorf("empty lastmod rendered:\n%s", out)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	urls := []URL{{Loc: "https://example.com/articles/a", LastMod: edited}}
+	same := []URL{{Loc: "https://example.com/articles/a", LastMod: edited.Add(time.Millisecond)}}
+	edit := []URL{{Loc: "https://example.com/articles/a", LastMod: edited.Add(time.Second)}}
+	if Fingerprint(urls) != Fingerprint(same) {
+		t.Error("sub-second changes are not rendered and should not change the fingerprint")
+	}
+	if Fingerprint(urls) == Fingerprint(edit) {
+		t.Error("a new lastmod should change the fingerprint")
+	}
+	if len(Fingerprint(nil)) != 64 {
+		t.Error("fingerprint should be a hex sha256")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Site:    &config.SiteConfig{Name: "Articles", BaseURL: "https://example.com"},
+		Sitemap: &config.SitemapConfig{Interval: 900},
+	}
+	store := &fakeStore{pages: []models.SitemapPage{{
+		Page: 1, URLCount: 1, Fingerprint: strings.Repeat("ab", 32), LastModified: edited, GeneratedAt: edited,
+		Body: []byte("<urlset/>"),
+	}}}
+	mux := http.NewServeMux()
+	NewHandler(cfg, store, log).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<loc>https://example.com/sitemaps/1.xml</loc>") {
+		t.Fatalf("index: got %d\n%s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sitemaps/1.xml", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "<urlset/>" || rec.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("page: got %d %q", rec.Code, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/sitemaps/1.xml", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: got status %d", rec.Code)
+	}
+
+	for _, target := range []string{"/sitemaps/2.xml", "/sitemaps/01.xml", "/sitemaps/0.xml", "/sitemaps/1.txt"} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxLength    = 80
+	maxTagLength = 32
+	fallback     = "article"
+)
+
+// cyrillic maps Russian and Uzbek Cyrillic letters to the Uzbek Latin alphabet,
+// Uzbek oʻ and gʻ lose their apostrophe since it is not URL friendly
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "j",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "x", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "", 'ы': "i", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h",
+}
+
+// apostrophes are dropped instead of splitting words, so "oʻzbek" becomes "ozbek"
+var apostrophes = map[rune]bool{
+	'\'': true, 'ʻ': true, 'ʼ': true, '‘': true, '’': true, '`': true, 'ʹ': true,
+}
+
+// Make builds a lowercase, hyphen separated ASCII slug from a title
+func Make(title string) string {
+	if slug := build(title, maxLength); slug != "" {
+		return slug
+	}
+	return fallback
+}
+
+// Tag normalises a tag the same way, it is empty when nothing of the tag survives
+func Tag(tag string) string {
+	return build(tag, maxTagLength)
+}
+
+func build(title string, limit int) string {
+	var sb strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(title) {
+		if latin, ok := cyrillic[r]; ok {
+			writeWord(&sb, latin, &pendingHyphen)
+			continue
+		}
+		if apostrophes[r] || unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		word := ""
+		for _, d := range norm.NFD.String(string(r)) {
+			if d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)) {
+				word += string(d)
+			}
+		}
+		if word == "" {
+			pendingHyphen = true
+			continue
+		}
+		writeWord(&sb, word, &pendingHyphen)
+	}
+
+	slug := sb.String()
+	if len(slug) > limit {
+		slug = strings.TrimRight(slug[:limit], "-")
+	}
+	return slug
+}
+
+func writeWord(sb *strings.Builder, s string, pendingHyphen *bool) {
+	if *pendingHyphen && sb.Len() > 0 {
+		sb.WriteByte('-')
+	}
+	*pendingHyphen = false
+	sb.WriteString(s)
+}
+
+// WithSuffix returns base when it is free, otherwise the first of base-2, base-3, ... not in taken
+func WithSuffix(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	if !used[base] {
+		return base
+	}
+	for i := 2; ; i++ {
+		candidate := base + "-" + strconv.Itoa(i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
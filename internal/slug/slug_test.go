@@ -0,0 +1,62 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	cases := []struct {
+		title string
+		want  string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Go 1.24 -- what's new?  ", "go-1-24-whats-new"},
+		{"Привет, мир", "privet-mir"},
+		{"Щука и ёжик", "shuka-i-yojik"},
+		{"Ўзбекистон ҳақида қисқача", "ozbekiston-haqida-qisqacha"},
+		{"Gʻalaba va oʻzbek tili", "galaba-va-ozbek-tili"},
+		{"G'alaba va o‘zbek tili", "galaba-va-ozbek-tili"},
+		{"Crème brûlée à la café", "creme-brulee-a-la-cafe"},
+		{"Cafe\u0301 society", "cafe-society"},
+		{"!!!", "article"},
+		{"日本語", "article"},
+	}
+	for _, c := range cases {
+		if got := Make(c.title); got != c.want {
+			t.Errorf("Make(%q) = %q, want %q", c.title, got, c.want)
+		}
+	}
+}
+
+func TestMakeTruncates(t *testing.T) {
+	title := ""
+	for i := 0; i < 30; i++ {
+		title += "word "
+	}
+	got := Make(title)
+	if len(got) > maxLength || got[len(got)-1] == '-' {
+		t.Errorf("Make(long title) = %q, want at most %d chars without trailing hyphen", got, maxLength)
+	}
+}
+
+func TestTag(t *testing.T) {
+	cases := map[string]string{
+		"Go":                "go",
+		" Machine Learning": "machine-learning",
+		"C++":               "c",
+		"!!!":               "",
+		"Dasturlash":        "dasturlash",
+	}
+	for tag, want := range cases {
+		if got := Tag(tag); got != want {
+			t.Errorf("Tag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	if got := WithSuffix("go", nil); got != "go" {
+		t.Errorf("WithSuffix(go, nil) = %q", got)
+	}
+	if got := WithSuffix("go", []string{"go", "go-2", "go-4"}); got != "go-3" {
+		t.Errorf("WithSuffix(go, taken) = %q, want go-3", got)
+	}
+}
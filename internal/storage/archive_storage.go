@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type archiveStore struct {
+	db *gorm.DB
+}
+
+func NewArchiveStore(db *gorm.DB) repos.ArchiveStore {
+	return &archiveStore{db: db}
+}
+
+// Export streams every archived row to v from one repeatable-read snapshot so rows written
+// while the export runs never appear half way
+func (s *archiveStore) Export(ctx context.Context, v repos.ArchiveVisitor) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := exportTable(tx, "id", v.Article); err != nil {
+			return err
+		}
+		if err := exportTable(tx, "slug", v.Slug); err != nil {
+			return err
+		}
+		if err := exportTable(tx, "article_id, user_id, reaction_type", v.Reaction); err != nil {
+			return err
+		}
+		if err := exportTable(tx, "article_id, reaction_type", v.ReactionCount); err != nil {
+			return err
+		}
+		return exportTable(tx, "article_id, file_name", v.Picture)
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// ImportArticles also records each article's current slug so archives without slug history
+// still resolve
+func (s *archiveStore) ImportArticles(ctx context.Context, articles []*models.Article, dryRun bool) (int64, error) {
+	return importRows(ctx, s.db, articles, dryRun, func(tx *gorm.DB) error {
+		slugs := make([]models.ArticleSlug, 0, len(articles))
+		for _, article := range articles {
+			if article.Slug != "" {
+				slugs = append(slugs, models.ArticleSlug{Slug: article.Slug, ArticleID: article.ID, Base: article.Slug})
+			}
+		}
+		if len(slugs) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&slugs).Error
+	})
+}
+
+func (s *archiveStore) ImportSlugs(ctx context.Context, slugs []*models.ArticleSlug, dryRun bool) (int64, error) {
+	return importRows(ctx, s.db, slugs, dryRun, nil)
+}
+
+func (s *archiveStore) ImportReactions(ctx context.Context, reactions []*models.ArticleReaction, dryRun bool) (int64, error) {
+	return importRows(ctx, s.db, reactions, dryRun, nil)
+}
+
+func (s *archiveStore) ImportReactionCounts(ctx context.Context, counts []*models.ArticleReactionCount, dryRun bool) (int64, error) {
+	return importRows(ctx, s.db, counts, dryRun, nil)
+}
+
+func (s *archiveStore) ImportPictures(ctx context.Context, pictures []*models.Picture, dryRun bool) (int64, error) {
+	return importRows(ctx, s.db, pictures, dryRun, nil)
+}
+
+// exportTable streams a table row by row with a server side cursor instead of loading it
+func exportTable[T any](tx *gorm.DB, order string, fn func(*T) error) error {
+	if fn == nil {
+		return nil
+	}
+	rows, err := tx.Model(new(T)).Order(order).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row := new(T)
+		if err := tx.ScanRows(rows, row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// importRows inserts rows in one transaction skipping conflicts, after runs in the same transaction
+func importRows[T any](ctx context.Context, db *gorm.DB, rows []*T, dryRun bool, after func(tx *gorm.DB) error) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	var inserted int64
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows)
+		if result.Error != nil {
+			return result.Error
+		}
+		inserted = result.RowsAffected
+		if after != nil {
+			if err := after(tx); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return 0, err
+	}
+	return inserted, nil
+}
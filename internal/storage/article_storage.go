@@ -4,41 +4,112 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
-	"github.com/k0kubun/pp"
 	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/content"
 	"github.com/ruziba3vich/mm_article_service/internal/models"
 	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const defaultReconcileBatchSize = 500
+
+// errDryRun rolls back a transaction whose changes were only counted
+var errDryRun = errors.New("dry run, rolling back")
+
+// basicViewColumns are the article columns read for ARTICLE_VIEW_BASIC, the body columns are left out
+var basicViewColumns = []string{
+	"id", "user_id", "original_article_id", "title", "slug", "content_format",
+	"word_count", "reading_time_minutes", "excerpt", "created_at", "updated_at", "edited_at", "content_revision",
+	"likes_count", "rewrites_count", "rewrite_policy", "license", "attribution", "status", "tags", "version",
+}
+
 // articleRepository implements ArticleRepo
 type articleRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	ids repos.IDGenerator
 }
 
 // NewArticleRepository creates a new articleRepository
-func NewArticleRepository(db *gorm.DB) repos.ArticleRepo {
-	return &articleRepository{db: db}
+func NewArticleRepository(db *gorm.DB, ids repos.IDGenerator) repos.ArticleRepo {
+	return &articleRepository{db: db, ids: ids}
 }
 
 // CreateArticle stores a new article
 func (r *articleRepository) CreateArticle(ctx context.Context, in *article_protos.CreateArticleRequest) (*article_protos.ArticleEntity, error) {
+	return r.createArticle(ctx, in, time.Time{}, nil)
+}
+
+// CreateImportedArticle stores an article brought over from another platform, keeping its original
+// date when createdAt is set. A picture row is written for every stored image name together with
+// the article, so a failed import leaves neither behind
+func (r *articleRepository) CreateImportedArticle(ctx context.Context, in *article_protos.CreateArticleRequest, createdAt time.Time, pictures []string) (*article_protos.ArticleEntity, error) {
+	return r.createArticle(ctx, in, createdAt, pictures)
+}
+
+func (r *articleRepository) createArticle(ctx context.Context, in *article_protos.CreateArticleRequest, createdAt time.Time, pictures []string) (*article_protos.ArticleEntity, error) {
 	if in.UserId == "" || in.Title == "" || in.Content == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id, title, and content are required")
 	}
 
-	article := models.Article{
-		ID:      generateULID(),
-		UserID:  in.UserId,
-		Title:   in.Title,
-		Content: in.Content,
+	policy, license, err := rewriteTerms(in.RewritePolicy, in.License, models.RewriteAllowed, models.LicenseAllRightsReserved)
+	if err != nil {
+		return nil, err
+	}
+	format, rendered, err := renderContent(in.ContentFormat, content.FormatPlain, in.Content)
+	if err != nil {
+		return nil, err
 	}
-	if err := r.db.WithContext(ctx).Create(&article).Error; err != nil {
+	articleStatus, err := statusOrDefault(in.Status, models.ArticlePublished)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := articleTags(in.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	article := models.Article{
+		ID:              r.ids.NewID(),
+		UserID:          in.UserId,
+		Title:           in.Title,
+		Content:         in.Content,
+		RewritePolicy:   policy,
+		License:         license,
+		ContentRevision: 1,
+		Status:          articleStatus,
+		Tags:            tags,
+		CreatedAt:       createdAt,
+		EditedAt:        createdAt,
+	}
+	if article.EditedAt.IsZero() {
+		article.EditedAt = time.Now()
+	}
+	article.SetRendered(format, rendered)
+	err = r.withSlugRetry(ctx, func(tx *gorm.DB) error {
+		if article.Slug, err = assignSlug(tx, article.ID, article.Title); err != nil {
+			return err
+		}
+		if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
+		if len(pictures) == 0 {
+			return nil
+		}
+		rows := make([]models.Picture, len(pictures))
+		for i, fileName := range pictures {
+			rows[i] = models.Picture{FileName: fileName, ArticleID: article.ID}
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create article: %v", err)
 	}
 
@@ -51,17 +122,112 @@ func (r *articleRepository) UpdateArticle(ctx context.Context, in *article_proto
 		return nil, status.Error(codes.InvalidArgument, "article_id, title, and content are required")
 	}
 
+	currentFormat := ""
+	if in.ContentFormat == article_protos.ContentFormat_CONTENT_FORMAT_UNSPECIFIED {
+		if err := r.db.WithContext(ctx).Model(&models.Article{}).Select("content_format").
+			Where("id = ?", in.ArticleId).Scan(&currentFormat).Error; err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch article: %v", err)
+		}
+		if currentFormat == "" {
+			return nil, status.Error(codes.NotFound, "article not found")
+		}
+	}
+	format, rendered, err := renderContent(in.ContentFormat, currentFormat, in.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// derived columns come from SetRendered like on create and rewrite
+	var edited models.Article
+	edited.SetRendered(format, rendered)
 	updates := map[string]any{
-		"title":      in.Title,
-		"content":    in.Content,
-		"updated_at": time.Now(),
+		"title":                in.Title,
+		"content":              in.Content,
+		"content_format":       edited.ContentFormat,
+		"content_html":         edited.ContentHTML,
+		"word_count":           edited.WordCount,
+		"reading_time_minutes": edited.ReadingTimeMinutes,
+		"excerpt":              edited.Excerpt,
+		"updated_at":           time.Now(),
+		"edited_at":            time.Now(),
+		"content_revision":     gorm.Expr("content_revision + 1"),
+	}
+	if in.RewritePolicy != article_protos.RewritePolicy_REWRITE_POLICY_UNSPECIFIED {
+		policy, _, err := rewriteTerms(in.RewritePolicy, article_protos.License_LICENSE_UNSPECIFIED, "", "")
+		if err != nil {
+			return nil, err
+		}
+		updates["rewrite_policy"] = policy
 	}
-	result := r.db.WithContext(ctx).Model(&models.Article{}).Where("id = ?", in.ArticleId).Updates(updates)
-	if result.Error != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update article: %v", result.Error)
+	if in.License != article_protos.License_LICENSE_UNSPECIFIED {
+		license, ok := models.LicenseFromProto(in.License)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "unknown license")
+		}
+		if err := r.checkShareAlike(ctx, in.ArticleId, license); err != nil {
+			return nil, err
+		}
+		updates["license"] = license
 	}
-	if result.RowsAffected == 0 {
-		return nil, status.Error(codes.NotFound, "article not found")
+	newStatus := ""
+	if in.Status != article_protos.ArticleStatus_ARTICLE_STATUS_UNSPECIFIED {
+		articleStatus, ok := models.StatusFromProto(in.Status)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "unknown status")
+		}
+		updates["status"] = articleStatus
+		newStatus = articleStatus
+	}
+	err = r.withSlugRetry(ctx, func(tx *gorm.DB) error {
+		var lineage models.Article
+		if newStatus != "" {
+			if err := tx.Select("original_article_id").Where("id = ?", in.ArticleId).Take(&lineage).Error; err != nil {
+				return err
+			}
+			// the original counts published rewrites only, so its counter moves with this status
+			if lineage.OriginalArticleID != "" {
+				if err := lockArticles(tx, lineage.OriginalArticleID, in.ArticleId); err != nil {
+					return err
+				}
+			}
+		}
+		var current models.Article
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "slug", "content_revision", "status").
+			Where("id = ?", in.ArticleId).Take(&current).Error; err != nil {
+			return err
+		}
+		if in.ExpectedRevision != 0 && in.ExpectedRevision != current.ContentRevision {
+			return &staleRevisionError{current: current.ContentRevision}
+		}
+		// a new title gets a new slug, the old one stays in the history and keeps resolving
+		s, err := assignSlug(tx, in.ArticleId, in.Title)
+		if err != nil {
+			return err
+		}
+		if s != current.Slug {
+			updates["slug"] = s
+		}
+		if err := tx.Model(&models.Article{}).Where("id = ?", in.ArticleId).Updates(updates).Error; err != nil {
+			return err
+		}
+		if lineage.OriginalArticleID == "" || newStatus == current.Status {
+			return nil
+		}
+		delta := gorm.Expr("rewrites_count + 1")
+		if current.Status == models.ArticlePublished {
+			delta = gorm.Expr("rewrites_count - 1")
+		}
+		return tx.Model(&models.Article{}).Where("id = ?", lineage.OriginalArticleID).Update("rewrites_count", delta).Error
+	})
+	if err != nil {
+		var stale *staleRevisionError
+		if errors.As(err, &stale) {
+			return nil, stale.status()
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "article not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update article: %v", err)
 	}
 
 	var article models.Article
@@ -74,15 +240,24 @@ func (r *articleRepository) UpdateArticle(ctx context.Context, in *article_proto
 	return article.ToArticleEntity(), nil
 }
 
-// RewriteArticle stores a new article with original_article_id
-func (r *articleRepository) RewriteArticle(ctx context.Context, in *article_protos.RewriteArticleRequest) (*article_protos.ArticleEntity, error) {
+// RewriteArticle stores a new article with original_article_id, permission and license checks are the caller's job
+func (r *articleRepository) RewriteArticle(ctx context.Context, in *article_protos.RewriteArticleRequest, attribution string) (*article_protos.ArticleEntity, error) {
 	if in.UserId == "" || in.OriginalArticleId == "" || in.Title == "" || in.Content == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id, original_article_id, title, and content are required")
 	}
 
-	// Verify original article exists
+	policy, license, err := rewriteTerms(in.RewritePolicy, in.License, models.RewriteAllowed, models.LicenseAllRightsReserved)
+	if err != nil {
+		return nil, err
+	}
+	format, rendered, err := renderContent(in.ContentFormat, content.FormatPlain, in.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify original article exists, drafts cannot be rewritten
 	var original models.Article
-	if err := r.db.WithContext(ctx).Where("id = ?", in.OriginalArticleId).First(&original).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ? AND status = ?", in.OriginalArticleId, models.ArticlePublished).First(&original).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, status.Error(codes.NotFound, "original article not found")
 		}
@@ -90,13 +265,31 @@ func (r *articleRepository) RewriteArticle(ctx context.Context, in *article_prot
 	}
 
 	article := models.Article{
-		ID:                generateULID(),
+		ID:                r.ids.NewID(),
 		UserID:            in.UserId,
 		OriginalArticleID: in.OriginalArticleId,
 		Title:             in.Title,
 		Content:           in.Content,
-	}
-	if err := r.db.WithContext(ctx).Create(&article).Error; err != nil {
+		RewritePolicy:     policy,
+		License:           license,
+		Attribution:       attribution,
+		ContentRevision:   1,
+		EditedAt:          time.Now(),
+	}
+	article.SetRendered(format, rendered)
+	err = r.withSlugRetry(ctx, func(tx *gorm.DB) error {
+		var err error
+		if article.Slug, err = assignSlug(tx, article.ID, article.Title); err != nil {
+			return err
+		}
+		if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
+		// a rewrite is created published, so it always counts towards the original
+		return tx.Model(&models.Article{}).Where("id = ?", in.OriginalArticleId).
+			Update("rewrites_count", gorm.Expr("rewrites_count + 1")).Error
+	})
+	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create rewritten article: %v", err)
 	}
 	return article.ToArticleEntity(), nil
@@ -108,70 +301,55 @@ func (r *articleRepository) DeleteArticle(ctx context.Context, in *article_proto
 		return nil, status.Error(codes.InvalidArgument, "article_id is required")
 	}
 
-	result := r.db.WithContext(ctx).Where("id = ?", in.ArticleId).Delete(&models.Article{})
-	if result.Error != nil {
-		return nil, status.Errorf(codes.Internal, "failed to delete article: %v", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return nil, status.Error(codes.NotFound, "article not found")
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var article models.Article
+		if err := tx.Select("id", "original_article_id", "status").Where("id = ?", in.ArticleId).First(&article).Error; err != nil {
+			return err
+		}
+		if article.OriginalArticleID != "" {
+			if err := lockArticles(tx, article.OriginalArticleID, article.ID); err != nil {
+				return err
+			}
+			// the status read above may have changed before the lock was taken
+			if err := tx.Select("status").Where("id = ?", in.ArticleId).Take(&article).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id = ?", in.ArticleId).Delete(&models.Article{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", in.ArticleId).Delete(&models.ArticleSlug{}).Error; err != nil {
+			return err
+		}
+		// only published rewrites are counted, see UpdateArticle
+		if article.OriginalArticleID == "" || article.Status != models.ArticlePublished {
+			return nil
+		}
+		return tx.Model(&models.Article{}).Where("id = ?", article.OriginalArticleID).
+			Update("rewrites_count", gorm.Expr("rewrites_count - 1")).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, "article not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete article: %v", err)
 	}
 	return &article_protos.DeleteArticleResponse{Success: true}, nil
 }
 
-// LikeArticle adds a like
+// LikeArticle adds a like, stored as the "like" reaction
 func (r *articleRepository) LikeArticle(ctx context.Context, in *article_protos.LikeArticleRequest) (*article_protos.LikeArticleResponse, error) {
 	if in.UserId == "" || in.ArticleId == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id and article_id are required")
 	}
 
-	var article models.Article
-	if err := r.db.WithContext(ctx).Where("id = ?", in.ArticleId).First(&article).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, status.Error(codes.NotFound, "article not found")
+	if _, err := r.addReaction(ctx, in.UserId, in.ArticleId, models.ReactionLike, 1); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return nil, status.Error(codes.AlreadyExists, "user has already liked this article")
 		}
-		return nil, status.Errorf(codes.Internal, "failed to verify article: %v", err)
-	}
-
-	hasLiked, err := r.HasUserLikedArticle(ctx, in.UserId, in.ArticleId)
-	if err != nil {
 		return nil, err
 	}
-	if hasLiked {
-		return nil, status.Error(codes.AlreadyExists, "user has already liked this article")
-	}
-
-	const maxRetries = 3 // TODO: get this value from config
-	for i := 0; i < maxRetries; i++ {
-		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			like := models.ArticleLike{
-				UserID:    in.UserId,
-				ArticleID: in.ArticleId,
-			}
-			if err := tx.Create(&like).Error; err != nil {
-				return err
-			}
-
-			result := tx.Model(&models.Article{}).
-				Where("id = ? AND version = ?", in.ArticleId, article.Version).
-				Updates(map[string]any{
-					"likes_count": gorm.Expr("likes_count + 1"),
-					"version":     gorm.Expr("version + 1"),
-				})
-
-			if result.Error != nil {
-				return result.Error
-			}
-			if result.RowsAffected == 0 {
-				return errors.New("concurrent update, retrying")
-			}
-			return nil
-		})
-		if err == nil {
-			return &article_protos.LikeArticleResponse{Success: true}, nil
-		}
-	}
-
-	return nil, status.Errorf(codes.Aborted, "failed to like article after retries: %v", err)
+	return &article_protos.LikeArticleResponse{Success: true}, nil
 }
 
 // UnlikeArticle removes a like
@@ -180,46 +358,10 @@ func (r *articleRepository) UnlikeArticle(ctx context.Context, in *article_proto
 		return nil, status.Error(codes.InvalidArgument, "user_id and article_id are required")
 	}
 
-	var article models.Article
-	if err := r.db.WithContext(ctx).Where("id = ?", in.ArticleId).First(&article).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, status.Error(codes.NotFound, "article not found")
-		}
-		return nil, status.Errorf(codes.Internal, "failed to verify article: %v", err)
-	}
-
-	const maxRetries = 3 // TODO: get this value from config
-	for i := 0; i < maxRetries; i++ {
-		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			result := tx.Where("user_id = ? AND article_id = ?", in.UserId, in.ArticleId).Delete(&models.ArticleLike{})
-			if result.Error != nil {
-				return result.Error
-			}
-			if result.RowsAffected == 0 {
-				return status.Error(codes.NotFound, "like not found")
-			}
-
-			update := tx.Model(&models.Article{}).
-				Where("id = ? AND version = ?", in.ArticleId, article.Version).
-				Updates(map[string]interface{}{
-					"likes_count": gorm.Expr("likes_count - 1"),
-					"version":     gorm.Expr("version + 1"),
-				})
-
-			if update.Error != nil {
-				return update.Error
-			}
-			if update.RowsAffected == 0 {
-				return errors.New("concurrent update, retrying")
-			}
-			return nil
-		})
-		if err == nil {
-			return &article_protos.UnlikeArticleResponse{Success: true}, nil
-		}
+	if _, err := r.removeReaction(ctx, in.UserId, in.ArticleId, models.ReactionLike); err != nil {
+		return nil, err
 	}
-
-	return nil, status.Error(codes.Aborted, "failed to unlike article after retries")
+	return &article_protos.UnlikeArticleResponse{Success: true}, nil
 }
 
 // GetArticlesByUser fetches articles by user
@@ -240,11 +382,18 @@ func (r *articleRepository) GetArticlesByUser(ctx context.Context, in *article_p
 			return err
 		}
 
-		if err := tx.Model(&models.Article{}).Where("user_id = ?", in.UserId).Count(&totalCount).Error; err != nil {
+		byUser := func(db *gorm.DB) *gorm.DB {
+			db = db.Where("user_id = ?", in.UserId)
+			if !in.IncludeDrafts || in.ViewerId != in.UserId {
+				db = db.Where("status = ?", models.ArticlePublished)
+			}
+			return db
+		}
+		if err := tx.Model(&models.Article{}).Scopes(byUser).Count(&totalCount).Error; err != nil {
 			return err
 		}
 
-		if err := tx.Where("user_id = ?", in.UserId).
+		if err := selectView(tx, in.View).Scopes(byUser).
 			Offset(int(offset)).Limit(int(in.Pagination.PageSize)).Order("created_at DESC").Find(&articles).Error; err != nil {
 			return err
 		}
@@ -255,16 +404,11 @@ func (r *articleRepository) GetArticlesByUser(ctx context.Context, in *article_p
 	}
 
 	protoArticles := make([]*article_protos.ArticleEntity, len(articles))
-	for i, a := range articles {
-		protoArticles[i] = &article_protos.ArticleEntity{
-			Id:                a.ID,
-			UserId:            a.UserID,
-			OriginalArticleId: a.OriginalArticleID,
-			Title:             a.Title,
-			Content:           a.Content,
-			CreatedAt:         timestamppb.New(a.CreatedAt),
-			LikeCount:         int32(a.LikesCount),
-		}
+	for i := range articles {
+		protoArticles[i] = articles[i].ToArticleEntity()
+	}
+	if err := r.attachReactionCounts(ctx, protoArticles...); err != nil {
+		return nil, err
 	}
 	return &article_protos.GetArticlesByUserResponse{
 		Pagination: &article_protos.PaginationResponse{
@@ -291,11 +435,11 @@ func (r *articleRepository) GetArticles(ctx context.Context, in *article_protos.
 			return err
 		}
 
-		if err := tx.Model(&models.Article{}).Count(&totalCount).Error; err != nil {
+		if err := tx.Model(&models.Article{}).Where("status = ?", models.ArticlePublished).Count(&totalCount).Error; err != nil {
 			return err
 		}
 
-		if err := tx.Offset(int(offset)).Limit(int(in.Pagination.PageSize)).Order("created_at DESC").Find(&articles).Error; err != nil {
+		if err := selectView(tx, in.View).Where("status = ?", models.ArticlePublished).Offset(int(offset)).Limit(int(in.Pagination.PageSize)).Order("created_at DESC").Find(&articles).Error; err != nil {
 			return err
 		}
 		return nil
@@ -305,16 +449,11 @@ func (r *articleRepository) GetArticles(ctx context.Context, in *article_protos.
 	}
 
 	protoArticles := make([]*article_protos.ArticleEntity, len(articles))
-	for i, a := range articles {
-		protoArticles[i] = &article_protos.ArticleEntity{
-			Id:                a.ID,
-			UserId:            a.UserID,
-			OriginalArticleId: a.OriginalArticleID,
-			Title:             a.Title,
-			Content:           a.Content,
-			CreatedAt:         timestamppb.New(a.CreatedAt),
-			LikeCount:         int32(a.LikesCount),
-		}
+	for i := range articles {
+		protoArticles[i] = articles[i].ToArticleEntity()
+	}
+	if err := r.attachReactionCounts(ctx, protoArticles...); err != nil {
+		return nil, err
 	}
 	return &article_protos.GetArticlesResponse{
 		Pagination: &article_protos.PaginationResponse{
@@ -326,6 +465,17 @@ func (r *articleRepository) GetArticles(ctx context.Context, in *article_protos.
 	}, nil
 }
 
+// visibleTo limits a query to published articles and to the drafts written by viewerID, a draft
+// read by anyone else is answered as not found
+func visibleTo(viewerID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == "" {
+			return db.Where("status = ?", models.ArticlePublished)
+		}
+		return db.Where("status = ? OR user_id = ?", models.ArticlePublished, viewerID)
+	}
+}
+
 // GetArticleByID fetches a single article
 func (r *articleRepository) GetArticleByID(ctx context.Context, in *article_protos.GetArticleByIDRequest) (*article_protos.GetArticleByIDResponse, error) {
 	if in.ArticleId == "" {
@@ -333,15 +483,19 @@ func (r *articleRepository) GetArticleByID(ctx context.Context, in *article_prot
 	}
 
 	var article models.Article
-	if err := r.db.WithContext(ctx).Where("id = ?", in.ArticleId).First(&article).Error; err != nil {
+	if err := selectView(r.db.WithContext(ctx), in.View).Where("id = ?", in.ArticleId).
+		Scopes(visibleTo(in.ViewerId)).First(&article).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, status.Error(codes.NotFound, "article not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to fetch article: %v", err)
 	}
-	pp.Println(article)
+	entity := article.ToArticleEntity()
+	if err := r.attachReactionCounts(ctx, entity); err != nil {
+		return nil, err
+	}
 	return &article_protos.GetArticleByIDResponse{
-		Article: article.ToArticleEntity(),
+		Article: entity,
 	}, nil
 }
 
@@ -352,30 +506,336 @@ func (r *articleRepository) HasUserLikedArticle(ctx context.Context, userID, art
 	}
 
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&models.ArticleLike{}).
-		Where("user_id = ? AND article_id = ?", userID, articleID).
+	if err := r.db.WithContext(ctx).Model(&models.ArticleReaction{}).
+		Where("user_id = ? AND article_id = ? AND reaction_type = ?", userID, articleID, models.ReactionLike).
 		Count(&count).Error; err != nil {
 		return false, status.Errorf(codes.Internal, "failed to check like status: %v", err)
 	}
 	return count > 0, nil
 }
 
-func generateULID() string {
-	now := time.Now()
-	timeComponent := fmt.Sprintf("%04d%02d%02d%02d%02d%02d%09d",
-		now.Year(), now.Month(), now.Day(),
-		now.Hour(), now.Minute(), now.Second(),
-		now.Nanosecond())
-	if len(timeComponent) > 32 {
-		timeComponent = timeComponent[:32]
-	}
-	for len(timeComponent) < 32 {
-		timeComponent += "0"
-	}
-	return fmt.Sprintf("%s-%s-%s-%s-%s",
-		timeComponent[0:8],
-		timeComponent[8:12],
-		timeComponent[12:16],
-		timeComponent[16:20],
-		timeComponent[20:32])
+// ListArticleLikers fetches the users who liked an article, newest first
+func (r *articleRepository) ListArticleLikers(ctx context.Context, in *article_protos.ListArticleLikersRequest) (*article_protos.ListArticleLikersResponse, error) {
+	if in.ArticleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "article_id is required")
+	}
+	limit := cursorLimit(in.Limit)
+
+	query := r.db.WithContext(ctx).Where("article_id = ? AND reaction_type = ?", in.ArticleId, models.ReactionLike)
+	if in.Cursor != "" {
+		createdAt, userID, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		query = query.Where("(created_at, user_id) < (?, ?)", createdAt, userID)
+	}
+
+	var likes []models.ArticleReaction
+	if err := query.Order("created_at DESC, user_id DESC").Limit(limit + 1).Find(&likes).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch article likers: %v", err)
+	}
+
+	resp := &article_protos.ListArticleLikersResponse{}
+	if len(likes) > limit {
+		likes = likes[:limit]
+		last := likes[len(likes)-1]
+		resp.NextCursor = encodeCursor(last.CreatedAt, last.UserID)
+	}
+	resp.Likers = make([]*article_protos.ArticleLiker, len(likes))
+	for i, l := range likes {
+		resp.Likers[i] = &article_protos.ArticleLiker{
+			UserId:  l.UserID,
+			LikedAt: timestamppb.New(l.CreatedAt),
+		}
+	}
+	return resp, nil
+}
+
+// ListLikedArticles fetches the articles a user has liked, most recently liked first
+func (r *articleRepository) ListLikedArticles(ctx context.Context, in *article_protos.ListLikedArticlesRequest) (*article_protos.ListLikedArticlesResponse, error) {
+	if in.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	limit := cursorLimit(in.Limit)
+
+	query := r.db.WithContext(ctx).Table("article_reactions").
+		Select("articles.*, article_reactions.created_at AS liked_at").
+		Joins("JOIN articles ON articles.id = article_reactions.article_id").
+		Where("article_reactions.user_id = ? AND article_reactions.reaction_type = ? AND articles.status = ?", in.UserId, models.ReactionLike, models.ArticlePublished)
+	if in.Cursor != "" {
+		likedAt, articleID, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		query = query.Where("(article_reactions.created_at, article_reactions.article_id) < (?, ?)", likedAt, articleID)
+	}
+
+	var liked []models.LikedArticle
+	if err := query.Order("article_reactions.created_at DESC, article_reactions.article_id DESC").Limit(limit + 1).Scan(&liked).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch liked articles: %v", err)
+	}
+
+	resp := &article_protos.ListLikedArticlesResponse{}
+	if len(liked) > limit {
+		liked = liked[:limit]
+		last := liked[len(liked)-1]
+		resp.NextCursor = encodeCursor(last.LikedAt, last.Article.ID)
+	}
+	resp.Articles = make([]*article_protos.ArticleEntity, len(liked))
+	for i := range liked {
+		entity := liked[i].Article.ToArticleEntity()
+		entity.Liked = true
+		resp.Articles[i] = entity
+	}
+	if err := r.attachReactionCounts(ctx, resp.Articles...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReconcileLikes recomputes likes_count and per-type reaction aggregates from article_reactions,
+// and rewrites_count from the published rewrites, in batches and fixes drifted counters. A dry run
+// rolls every batch back after counting
+func (r *articleRepository) ReconcileLikes(ctx context.Context, in *article_protos.ReconcileLikesRequest) (*article_protos.ReconcileLikesResponse, error) {
+	if in.BatchSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "batch_size must not be negative")
+	}
+	batchSize := int(in.BatchSize)
+	if batchSize == 0 {
+		batchSize = defaultReconcileBatchSize
+	}
+
+	var scanned, corrected, countsCorrected, rewritesCorrected int64
+	lastID := ""
+	for {
+		var ids []string
+		query := r.db.WithContext(ctx).Model(&models.Article{}).Order("id").Limit(batchSize)
+		if lastID != "" {
+			query = query.Where("id > ?", lastID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch article batch: %v", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		var likesFixed, countsFixed, rewritesFixed int64
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			if likesFixed, err = reconcileLikesCounts(tx, ids); err != nil {
+				return status.Errorf(codes.Internal, "failed to reconcile likes: %v", err)
+			}
+			if rewritesFixed, err = reconcileRewritesCounts(tx, ids); err != nil {
+				return status.Errorf(codes.Internal, "failed to reconcile rewrite counts: %v", err)
+			}
+			if countsFixed, err = reconcileReactionCounts(tx, ids); err != nil {
+				return status.Errorf(codes.Internal, "failed to reconcile reaction counts: %v", err)
+			}
+			if in.DryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			return nil, err
+		}
+
+		scanned += int64(len(ids))
+		corrected += likesFixed
+		countsCorrected += countsFixed
+		rewritesCorrected += rewritesFixed
+		lastID = ids[len(ids)-1]
+		if len(ids) < batchSize {
+			break
+		}
+	}
+
+	return &article_protos.ReconcileLikesResponse{
+		ArticlesScanned:         int32(scanned),
+		ArticlesCorrected:       int32(corrected),
+		ReactionCountsCorrected: int32(countsCorrected),
+		RewritesCountsCorrected: int32(rewritesCorrected),
+	}, nil
+}
+
+// reconcileLikesCounts rewrites likes_count for the given articles where it disagrees with article_reactions
+func reconcileLikesCounts(tx *gorm.DB, articleIDs []string) (int64, error) {
+	// reactions change the counters under the article row lock (see lockArticle), so once the batch
+	// is locked every reaction is either committed and seen by the counts below or waits and applies
+	// on top of the fix. Counting in the same statement as the lock would compare against the
+	// pre-lock snapshot. reconcileReactionCounts relies on this lock too and must run after it
+	if err := lockArticles(tx, articleIDs...); err != nil {
+		return 0, err
+	}
+	// version is bumped so that in-flight LikeArticle/UnlikeArticle retries re-read the fixed counter
+	result := tx.Exec(`
+		UPDATE articles AS a
+		SET likes_count = c.total, version = a.version + 1
+		FROM (
+			SELECT articles.id, COUNT(article_reactions.user_id) AS total
+			FROM articles
+			LEFT JOIN article_reactions
+				ON article_reactions.article_id = articles.id AND article_reactions.reaction_type = ?
+			WHERE articles.id IN ?
+			GROUP BY articles.id
+		) AS c
+		WHERE a.id = c.id AND a.likes_count <> c.total`, models.ReactionLike, articleIDs)
+	return result.RowsAffected, result.Error
+}
+
+// reconcileRewritesCounts rewrites rewrites_count where it disagrees with the published rewrites,
+// the ones ListArticleRewrites lists. Rewrites change the counter under the original's row lock, so
+// like reconcileReactionCounts this relies on the batch lock taken by reconcileLikesCounts
+func reconcileRewritesCounts(tx *gorm.DB, articleIDs []string) (int64, error) {
+	result := tx.Exec(`
+		UPDATE articles AS a
+		SET rewrites_count = c.total
+		FROM (
+			SELECT articles.id, COUNT(rewrites.id) AS total
+			FROM articles
+			LEFT JOIN articles AS rewrites
+				ON rewrites.original_article_id = articles.id AND rewrites.status = ?
+			WHERE articles.id IN ?
+			GROUP BY articles.id
+		) AS c
+		WHERE a.id = c.id AND a.rewrites_count <> c.total`, models.ArticlePublished, articleIDs)
+	return result.RowsAffected, result.Error
+}
+
+// reconcileReactionCounts rewrites article_reaction_counts rows that disagree with article_reactions
+func reconcileReactionCounts(tx *gorm.DB, articleIDs []string) (int64, error) {
+	upserted := tx.Exec(`
+		INSERT INTO article_reaction_counts (article_id, reaction_type, total, reactors)
+		SELECT article_id, reaction_type, SUM(count), COUNT(*)
+		FROM article_reactions
+		WHERE article_id IN ?
+		GROUP BY article_id, reaction_type
+		ON CONFLICT (article_id, reaction_type) DO UPDATE
+		SET total = EXCLUDED.total, reactors = EXCLUDED.reactors
+		WHERE article_reaction_counts.total <> EXCLUDED.total
+			OR article_reaction_counts.reactors <> EXCLUDED.reactors`, articleIDs)
+	if upserted.Error != nil {
+		return 0, upserted.Error
+	}
+
+	orphaned := tx.Exec(`
+		UPDATE article_reaction_counts AS c
+		SET total = 0, reactors = 0
+		WHERE c.article_id IN ?
+			AND (c.total <> 0 OR c.reactors <> 0)
+			AND NOT EXISTS (
+				SELECT 1 FROM article_reactions
+				WHERE article_reactions.article_id = c.article_id
+					AND article_reactions.reaction_type = c.reaction_type
+			)`, articleIDs)
+	if orphaned.Error != nil {
+		return 0, orphaned.Error
+	}
+	return upserted.RowsAffected + orphaned.RowsAffected, nil
+}
+
+// checkShareAlike rejects relicensing a rewrite whose original is share-alike
+func (r *articleRepository) checkShareAlike(ctx context.Context, articleID, license string) error {
+	var originalLicense string
+	err := r.db.WithContext(ctx).Table("articles").
+		Select("originals.license").
+		Joins("JOIN articles AS originals ON originals.id = articles.original_article_id").
+		Where("articles.id = ?", articleID).
+		Scan(&originalLicense).Error
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check original license: %v", err)
+	}
+	if models.LicenseIsShareAlike(originalLicense) && license != originalLicense {
+		return status.Error(codes.FailedPrecondition, "rewrites of share-alike articles must keep the original license")
+	}
+	return nil
+}
+
+// staleRevisionError rejects an edit based on an outdated content revision
+type staleRevisionError struct {
+	current int64
+}
+
+func (e *staleRevisionError) Error() string {
+	return fmt.Sprintf("article was edited concurrently, current revision is %d", e.current)
+}
+
+// status reports the conflict as FailedPrecondition with the current revision in an ErrorInfo
+// detail, so editors can show the conflict without parsing the message
+func (e *staleRevisionError) status() error {
+	st := status.New(codes.FailedPrecondition, e.Error())
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   "STALE_REVISION",
+		Domain:   "article_service",
+		Metadata: map[string]string{"current_revision": strconv.FormatInt(e.current, 10)},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// statusOrDefault resolves the requested article status, falling back when unspecified
+func statusOrDefault(s article_protos.ArticleStatus, fallback string) (string, error) {
+	if s == article_protos.ArticleStatus_ARTICLE_STATUS_UNSPECIFIED {
+		return fallback, nil
+	}
+	name, ok := models.StatusFromProto(s)
+	if !ok {
+		return "", status.Error(codes.InvalidArgument, "unknown status")
+	}
+	return name, nil
+}
+
+// articleTags normalises the requested tags and enforces the per-article limit
+func articleTags(tags []string) (models.Tags, error) {
+	normalized := models.NormalizeTags(tags)
+	if len(normalized) > models.MaxTags {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d tags are allowed", models.MaxTags)
+	}
+	return normalized, nil
+}
+
+// selectView narrows the selected article columns to what the requested view returns
+func selectView(db *gorm.DB, view article_protos.ArticleView) *gorm.DB {
+	if view == article_protos.ArticleView_ARTICLE_VIEW_BASIC {
+		return db.Select(basicViewColumns)
+	}
+	return db
+}
+
+// renderContent resolves the requested content format, falling back to the given one when unspecified,
+// and renders the sanitised HTML body
+func renderContent(f article_protos.ContentFormat, fallback, source string) (string, string, error) {
+	format := fallback
+	if f != article_protos.ContentFormat_CONTENT_FORMAT_UNSPECIFIED {
+		var ok bool
+		if format, ok = models.ContentFormatFromProto(f); !ok {
+			return "", "", status.Error(codes.InvalidArgument, "unknown content_format")
+		}
+	}
+	rendered, err := content.Render(format, source)
+	if err != nil {
+		return "", "", status.Errorf(codes.InvalidArgument, "failed to render content: %v", err)
+	}
+	return format, rendered, nil
+}
+
+// rewriteTerms resolves the requested rewrite policy and license, unspecified values fall back to the defaults
+func rewriteTerms(p article_protos.RewritePolicy, l article_protos.License, defaultPolicy, defaultLicense string) (string, string, error) {
+	policy, license := defaultPolicy, defaultLicense
+	if p != article_protos.RewritePolicy_REWRITE_POLICY_UNSPECIFIED {
+		var ok bool
+		if policy, ok = models.RewritePolicyFromProto(p); !ok {
+			return "", "", status.Error(codes.InvalidArgument, "unknown rewrite_policy")
+		}
+	}
+	if l != article_protos.License_LICENSE_UNSPECIFIED {
+		var ok bool
+		if license, ok = models.LicenseFromProto(l); !ok {
+			return "", "", status.Error(codes.InvalidArgument, "unknown license")
+		}
+	}
+	return policy, license, nil
 }
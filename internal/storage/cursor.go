@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCursorLimit = 20
+	maxCursorLimit     = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor builds an opaque keyset cursor from a timestamp and a tie-breaking id
+func encodeCursor(t time.Time, id string) string {
+	raw := strconv.FormatInt(t.UnixNano(), 10) + ":" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", errInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return time.Time{}, "", errInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", errInvalidCursor
+	}
+	return time.Unix(0, n), id, nil
+}
+
+// cursorLimit clamps a requested page size to sane bounds
+func cursorLimit(limit int32) int {
+	if limit <= 0 {
+		return defaultCursorLimit
+	}
+	if limit > maxCursorLimit {
+		return maxCursorLimit
+	}
+	return int(limit)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListArticleLikersPagesOnTheLastRow(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	at := time.Unix(1_700_000_000, 0)
+	// one row more than the limit means there is a next page, starting after the second row
+	mock.ExpectQuery(`SELECT \* FROM "article_reactions" WHERE article_id = \$1 AND reaction_type = \$2 ORDER BY created_at DESC, user_id DESC LIMIT \$3`).
+		WithArgs("a-1", models.ReactionLike, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "created_at"}).
+			AddRow("u-3", at).AddRow("u-2", at).AddRow("u-1", at))
+
+	resp, err := repo.ListArticleLikers(context.Background(), &article_protos.ListArticleLikersRequest{ArticleId: "a-1", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Likers) != 2 || resp.NextCursor != encodeCursor(at, "u-2") {
+		t.Fatalf("got %d likers, cursor %q", len(resp.Likers), resp.NextCursor)
+	}
+
+	// same timestamp, so the user id breaks the tie; a page of exactly the limit is the last one
+	mock.ExpectQuery(`AND \(created_at, user_id\) < \(\$3, \$4\)`).
+		WithArgs("a-1", models.ReactionLike, at, "u-2", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "created_at"}).AddRow("u-1", at))
+
+	resp, err = repo.ListArticleLikers(context.Background(), &article_protos.ListArticleLikersRequest{ArticleId: "a-1", Limit: 2, Cursor: resp.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Likers) != 1 || resp.NextCursor != "" {
+		t.Errorf("last page: got %d likers, cursor %q", len(resp.Likers), resp.NextCursor)
+	}
+
+	if _, err := repo.ListArticleLikers(context.Background(), &article_protos.ListArticleLikersRequest{ArticleId: "a-1", Cursor: "%%%"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("malformed cursor: got %v, want InvalidArgument", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListLikedArticlesCursorUsesLikeTime(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	liked := time.Unix(1_700_000_500, 0)
+	mock.ExpectQuery(`article_reactions.created_at DESC, article_reactions.article_id DESC LIMIT \$4`).
+		WithArgs("u-1", models.ReactionLike, models.ArticlePublished, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "liked_at"}).AddRow("a-2", liked).AddRow("a-1", liked))
+	expectReactionResponse(mock)
+
+	resp, err := repo.ListLikedArticles(context.Background(), &article_protos.ListLikedArticlesRequest{UserId: "u-1", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Articles) != 1 || resp.NextCursor != encodeCursor(liked, "a-2") {
+		t.Fatalf("got %d articles, cursor %q", len(resp.Articles), resp.NextCursor)
+	}
+
+	mock.ExpectQuery(`\(article_reactions.created_at, article_reactions.article_id\) < \(\$4, \$5\)`).
+		WithArgs("u-1", models.ReactionLike, models.ArticlePublished, liked, "a-2", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "liked_at"}))
+
+	resp, err = repo.ListLikedArticles(context.Background(), &article_protos.ListLikedArticlesRequest{UserId: "u-1", Limit: 1, Cursor: resp.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Articles) != 0 || resp.NextCursor != "" {
+		t.Errorf("past the end: got %d articles, cursor %q", len(resp.Articles), resp.NextCursor)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,21 +1,30 @@
 package storage
 
 import (
-	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"context"
+
 	"github.com/ruziba3vich/mm_article_service/pkg/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// NewGORM initializes a GORM database connection with migrations
+// NewGORM initializes a GORM database connection, refusing to start against an unmigrated schema
 func NewGORM(cfg *config.Config) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(cfg.PsqlCfg.Dsn), &gorm.Config{})
+	db, err := OpenGORM(cfg)
 	if err != nil {
 		return nil, err
 	}
-	err = db.AutoMigrate(&models.Article{}, &models.ArticleLike{}, &models.Picture{})
+	migrator, err := NewMigrator(db)
 	if err != nil {
 		return nil, err
 	}
+	if err := migrator.CheckSchema(context.Background()); err != nil {
+		return nil, err
+	}
 	return db, nil
 }
+
+// OpenGORM opens a GORM database connection without checking the schema
+func OpenGORM(cfg *config.Config) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(cfg.PsqlCfg.Dsn), &gorm.Config{})
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLikeArticleRejectsDrafts(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticleDraft)
+	mock.ExpectRollback()
+
+	_, err := repo.LikeArticle(context.Background(), &article_protos.LikeArticleRequest{UserId: "u-1", ArticleId: "a-1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("liking a draft: got %v, want NotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListLikedArticlesSkipsDrafts(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectQuery(`article_reactions.reaction_type = \$2 AND articles.status = \$3`).
+		WithArgs("u-1", models.ReactionLike, models.ArticlePublished, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	resp, err := repo.ListLikedArticles(context.Background(), &article_protos.ListLikedArticlesRequest{UserId: "u-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Articles) != 0 {
+		t.Errorf("got %d articles", len(resp.Articles))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetArticleByIDHidesDraftsFromOthers(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectQuery(`WHERE id = \$1 AND status = \$2`).
+		WithArgs("a-1", models.ArticlePublished, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetArticleByID(context.Background(), &article_protos.GetArticleByIDRequest{ArticleId: "a-1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("anonymous read of a draft: got %v, want NotFound", err)
+	}
+
+	mock.ExpectQuery(`WHERE id = \$1 AND \(status = \$2 OR user_id = \$3\)`).
+		WithArgs("a-1", models.ArticlePublished, "u-2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.GetArticleByID(context.Background(), &article_protos.GetArticleByIDRequest{ArticleId: "a-1", ViewerId: "u-2"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("stranger's read of a draft: got %v, want NotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetArticlesByUserIncludesDraftsOnlyForTheAuthor(t *testing.T) {
+	for _, tc := range []struct {
+		viewer string
+		args   []any
+	}{
+		{viewer: "u-2", args: []any{"u-1", models.ArticlePublished}},
+		{viewer: "u-1", args: []any{"u-1"}},
+	} {
+		repo, mock := newMockRepository(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET TRANSACTION`).WillReturnResult(sqlmock.NewResult(0, 0))
+		count := make([]driver.Value, len(tc.args))
+		for i := range tc.args {
+			count[i] = tc.args[i]
+		}
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "articles"`).WithArgs(count...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`FROM "articles"`).WithArgs(append(count, 10)...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		_, err := repo.GetArticlesByUser(context.Background(), &article_protos.GetArticlesByUserRequest{
+			UserId:        "u-1",
+			ViewerId:      tc.viewer,
+			IncludeDrafts: true,
+			Pagination:    &article_protos.PaginationRequest{Page: 1, PageSize: 10},
+		})
+		if err != nil {
+			t.Fatalf("viewer %s: %v", tc.viewer, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("viewer %s: %v", tc.viewer, err)
+		}
+	}
+}
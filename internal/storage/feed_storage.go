@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// feedColumns are the article columns a feed entry is built from
+var feedColumns = []string{"id", "user_id", "title", "slug", "excerpt", "created_at", "edited_at", "tags"}
+
+// FeedArticles fetches the newest published articles of an author or, when tag is set, of a tag
+func (r *articleRepository) FeedArticles(ctx context.Context, userID, tag string, limit int) ([]*models.Article, error) {
+	if (userID == "") == (tag == "") {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of user_id and tag is required")
+	}
+
+	query := r.db.WithContext(ctx).Select(feedColumns).Where("status = ?", models.ArticlePublished)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	} else {
+		// containment keeps the lookup on the tags GIN index
+		contains, err := json.Marshal([]string{tag})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode tag: %v", err)
+		}
+		query = query.Where("tags @> ?::jsonb", string(contains))
+	}
+
+	var articles []*models.Article
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&articles).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch feed articles: %v", err)
+	}
+	return articles, nil
+}
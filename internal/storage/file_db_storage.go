@@ -53,3 +53,52 @@ func (r *FileDbStorage) DeletePicture(ctx context.Context, fileName, articleID s
 	}
 	return nil
 }
+
+// ListOrphanPictures returns pictures whose article no longer exists
+func (r *FileDbStorage) ListOrphanPictures(ctx context.Context) ([]*models.Picture, error) {
+	var pictures []*models.Picture
+	err := r.db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM articles WHERE articles.id::text = pictures.article_id)").
+		Order("file_name").Find(&pictures).Error
+	if err != nil {
+		return nil, err
+	}
+	return pictures, nil
+}
+
+// ReferencedFiles reports which of the given object names are still used by a picture
+func (r *FileDbStorage) ReferencedFiles(ctx context.Context, fileNames []string) (map[string]bool, error) {
+	referenced := make(map[string]bool, len(fileNames))
+	if len(fileNames) == 0 {
+		return referenced, nil
+	}
+	var names []string
+	if err := r.db.WithContext(ctx).Model(&models.Picture{}).
+		Where("file_name IN ?", fileNames).Distinct().Pluck("file_name", &names).Error; err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		referenced[name] = true
+	}
+	return referenced, nil
+}
+
+// PublishedFiles reports which of the given object names belong to a published article, images
+// of a draft stay private until the draft is published
+func (r *FileDbStorage) PublishedFiles(ctx context.Context, fileNames []string) (map[string]bool, error) {
+	published := make(map[string]bool, len(fileNames))
+	if len(fileNames) == 0 {
+		return published, nil
+	}
+	var names []string
+	if err := r.db.WithContext(ctx).Model(&models.Picture{}).
+		Joins("JOIN articles ON articles.id::text = pictures.article_id").
+		Where("pictures.file_name IN ? AND articles.status = ?", fileNames, models.ArticlePublished).
+		Distinct().Pluck("pictures.file_name", &names).Error; err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		published[name] = true
+	}
+	return published, nil
+}
@@ -3,12 +3,18 @@ package storage
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
 	"github.com/ruziba3vich/mm_article_service/internal/repos"
 	"github.com/ruziba3vich/mm_article_service/pkg/config"
 )
@@ -18,6 +24,7 @@ type MinioStorage struct {
 	client     *minio.Client
 	bucketName string
 	urlExpiry  int64 // Seconds for presigned URL expiry
+	publicURL  string
 }
 
 // NewMinIOStorage initializes a MinIO client
@@ -43,6 +50,7 @@ func NewMinIOStorage(cfg *config.Config) (repos.MinIOStorage, error) {
 		client:     client,
 		bucketName: cfg.MinIO.Bucket,
 		urlExpiry:  int64(cfg.MinIO.UrlExpiry),
+		publicURL:  strings.TrimRight(cfg.MinIO.PublicURL, "/"),
 	}, nil
 }
 
@@ -74,3 +82,46 @@ func (s *MinioStorage) GetFileURL(ctx context.Context, fileName string) (string,
 	}
 	return url.String(), nil
 }
+
+// PublicURL joins the object name to the configured public bucket address
+func (s *MinioStorage) PublicURL(fileName string) string {
+	return s.publicURL + "/" + url.PathEscape(fileName)
+}
+
+// WalkFiles calls fn for every object in the bucket, stopping at the first error
+func (s *MinioStorage) WalkFiles(ctx context.Context, fn func(models.StoredFile) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return object.Err
+		}
+		if err := fn(models.StoredFile{Name: object.Key, Size: object.Size, LastModified: object.LastModified}); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// OpenFile streams an object, a missing object returns an error wrapping fs.ErrNotExist
+func (s *MinioStorage) OpenFile(ctx context.Context, fileName string) (io.ReadCloser, models.StoredFile, error) {
+	object, err := s.client.GetObject(ctx, s.bucketName, fileName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, models.StoredFile{}, err
+	}
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, models.StoredFile{}, fmt.Errorf("%w: %s", fs.ErrNotExist, fileName)
+		}
+		return nil, models.StoredFile{}, err
+	}
+	return object, models.StoredFile{Name: fileName, Size: info.Size, LastModified: info.LastModified}, nil
+}
+
+// PutFile stores an object under exactly the given name, replacing any existing one
+func (s *MinioStorage) PutFile(ctx context.Context, fileName string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucketName, fileName, r, size, minio.PutObjectOptions{})
+	return err
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errClaimLost is returned when a pending key was taken over before its request finished
+var errClaimLost = errors.New("idempotency key was claimed by another request")
+
+// idempotencyStorage implements IdempotencyStore on Postgres
+type idempotencyStorage struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyStore creates a new idempotencyStorage
+func NewIdempotencyStore(db *gorm.DB) repos.IdempotencyStore {
+	return &idempotencyStorage{db: db}
+}
+
+// Reserve inserts a pending record, taking over rows that expired or were abandoned while pending
+func (s *idempotencyStorage) Reserve(ctx context.Context, caller, key, method, fingerprint string, ttl, pendingTimeout time.Duration) (*models.IdempotencyKey, bool, error) {
+	const maxAttempts = 2
+	for i := 0; i < maxAttempts; i++ {
+		now := time.Now()
+		record := models.IdempotencyKey{
+			Caller:      caller,
+			Key:         key,
+			Method:      method,
+			Fingerprint: fingerprint,
+			Token:       uuid.NewString(),
+			State:       models.IdempotencyPending,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "caller"}, {Name: "key"}, {Name: "method"}},
+			DoUpdates: clause.AssignmentColumns([]string{"fingerprint", "token", "state", "response_type", "response", "created_at", "expires_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{gorm.Expr(
+				"idempotency_keys.expires_at < ? OR (idempotency_keys.state = ? AND idempotency_keys.created_at < ?)",
+				now, models.IdempotencyPending, now.Add(-pendingTimeout),
+			)}},
+		}).Create(&record)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return &record, true, nil
+		}
+
+		var existing models.IdempotencyKey
+		err := s.db.WithContext(ctx).Where("caller = ? AND key = ? AND method = ?", caller, key, method).Take(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// released between the insert and the read, try to claim it again
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	}
+	return nil, false, errors.New("idempotency key changed hands concurrently")
+}
+
+// Complete stores the response of a successful request, unless its claim was taken over meanwhile
+func (s *idempotencyStorage) Complete(ctx context.Context, claim *models.IdempotencyKey, responseType string, response []byte) error {
+	result := s.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("caller = ? AND key = ? AND method = ? AND token = ?", claim.Caller, claim.Key, claim.Method, claim.Token).
+		Updates(map[string]any{
+			"state":         models.IdempotencyCompleted,
+			"response_type": responseType,
+			"response":      response,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errClaimLost
+	}
+	return nil
+}
+
+// Release forgets a pending key so the request can be retried, used when the request failed.
+// A claim that was taken over is left to the request now holding it
+func (s *idempotencyStorage) Release(ctx context.Context, claim *models.IdempotencyKey) error {
+	return s.db.WithContext(ctx).
+		Where("caller = ? AND key = ? AND method = ? AND token = ? AND state = ?",
+			claim.Caller, claim.Key, claim.Method, claim.Token, models.IdempotencyPending).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// PurgeExpired deletes records past their replay window
+func (s *idempotencyStorage) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
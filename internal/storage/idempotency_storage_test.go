@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
+
+func TestIdempotencyCompleteRequiresTheClaimToken(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	store := NewIdempotencyStore(repo.db)
+	claim := &models.IdempotencyKey{Caller: "u-1", Key: "k", Method: "/m", Token: "t-1"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "idempotency_keys" SET .* WHERE caller = \$4 AND key = \$5 AND method = \$6 AND token = \$7`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "u-1", "k", "/m", "t-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := store.Complete(context.Background(), claim, "type", []byte("resp")); !errors.Is(err, errClaimLost) {
+		t.Errorf("completing a taken over claim: got %v, want errClaimLost", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "idempotency_keys" WHERE caller = \$1 AND key = \$2 AND method = \$3 AND token = \$4 AND state = \$5`).
+		WithArgs("u-1", "k", "/m", "t-1", models.IdempotencyPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := store.Release(context.Background(), claim); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/idgen"
+)
+
+func expectImportedArticle(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "article_slugs"`).WillReturnRows(sqlmock.NewRows([]string{"slug", "article_id", "base"}))
+	mock.ExpectExec(`INSERT INTO "article_slugs"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "articles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"original_article_id", "slug"}).AddRow(nil, "hello"))
+}
+
+func importRequest() *article_protos.CreateArticleRequest {
+	return &article_protos.CreateArticleRequest{
+		UserId:        "u-1",
+		Title:         "Hello",
+		Content:       "![cover](http://files/cover.png)",
+		ContentFormat: article_protos.ContentFormat_CONTENT_FORMAT_MARKDOWN,
+		Status:        article_protos.ArticleStatus_ARTICLE_STATUS_DRAFT,
+	}
+}
+
+func TestCreateImportedArticleStoresPicturesInTheSameTransaction(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	repo.ids = idgen.NewSequential(1)
+	expectImportedArticle(mock)
+	mock.ExpectExec(`INSERT INTO "pictures" \("file_name","article_id"\) VALUES \(\$1,\$2\),\(\$3,\$4\)`).
+		WithArgs("cover.png", sqlmock.AnyArg(), "chart.png", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if _, err := repo.CreateImportedArticle(context.Background(), importRequest(), time.Time{}, []string{"cover.png", "chart.png"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateImportedArticleRollsBackWhenPicturesFail(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	repo.ids = idgen.NewSequential(1)
+	expectImportedArticle(mock)
+	mock.ExpectExec(`INSERT INTO "pictures"`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	if _, err := repo.CreateImportedArticle(context.Background(), importRequest(), time.Time{}, []string{"cover.png"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDerivationDepth = 3
+	maxDerivationDepth     = 10
+	maxDerivationNodes     = 1_000
+	// maxAncestryDepth guards the recursive walk against corrupted, cyclic links
+	maxAncestryDepth = 1_000
+)
+
+// ListArticleRewrites fetches the published direct rewrites of an article
+func (r *articleRepository) ListArticleRewrites(ctx context.Context, in *article_protos.ListArticleRewritesRequest) (*article_protos.ListArticleRewritesResponse, error) {
+	if in.ArticleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "article_id is required")
+	}
+	if in.Pagination.GetPage() <= 0 || in.Pagination.GetPageSize() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid pagination parameters")
+	}
+
+	var articles []models.Article
+	var totalCount int64
+	offset := (in.Pagination.Page - 1) * in.Pagination.PageSize
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Article{}).Where("original_article_id = ? AND status = ?", in.ArticleId, models.ArticlePublished).Count(&totalCount).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("original_article_id = ? AND status = ?", in.ArticleId, models.ArticlePublished).
+			Offset(int(offset)).Limit(int(in.Pagination.PageSize)).Order("created_at DESC").Find(&articles).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch rewrites: %v", err)
+	}
+
+	protoArticles := make([]*article_protos.ArticleEntity, len(articles))
+	for i := range articles {
+		protoArticles[i] = articles[i].ToArticleEntity()
+	}
+	if err := r.attachReactionCounts(ctx, protoArticles...); err != nil {
+		return nil, err
+	}
+	return &article_protos.ListArticleRewritesResponse{
+		Pagination: &article_protos.PaginationResponse{
+			Articles:   protoArticles,
+			TotalCount: int32(totalCount),
+			Page:       in.Pagination.Page,
+			PageSize:   in.Pagination.PageSize,
+		},
+	}, nil
+}
+
+// GetArticleAncestry walks original_article_id links from a rewrite up to the root article, an
+// ancestor that went back to draft keeps its place in the walk but is left out of the result
+func (r *articleRepository) GetArticleAncestry(ctx context.Context, in *article_protos.GetArticleAncestryRequest) (*article_protos.GetArticleAncestryResponse, error) {
+	if in.ArticleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "article_id is required")
+	}
+	if err := r.ensureArticleExists(ctx, in.ArticleId); err != nil {
+		return nil, err
+	}
+
+	var chain []models.LineageArticle
+	if err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE ancestry AS (
+			SELECT parent.*, 1 AS depth
+			FROM articles AS child
+			JOIN articles AS parent ON parent.id = child.original_article_id
+			WHERE child.id = ?
+			UNION ALL
+			SELECT parent.*, ancestry.depth + 1
+			FROM articles AS parent
+			JOIN ancestry ON parent.id = ancestry.original_article_id
+			WHERE ancestry.depth < ?
+		)
+		SELECT * FROM ancestry WHERE status = ? ORDER BY depth`, in.ArticleId, maxAncestryDepth, models.ArticlePublished).
+		Scan(&chain).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch article ancestry: %v", err)
+	}
+
+	ancestors := make([]*article_protos.ArticleEntity, len(chain))
+	for i := range chain {
+		ancestors[i] = chain[i].Article.ToArticleEntity()
+	}
+	if err := r.attachReactionCounts(ctx, ancestors...); err != nil {
+		return nil, err
+	}
+	return &article_protos.GetArticleAncestryResponse{Ancestors: ancestors}, nil
+}
+
+// GetDerivationTree returns a published article with its rewrites, their rewrites and so on down
+// to max_depth. A draft is left out together with everything below it
+func (r *articleRepository) GetDerivationTree(ctx context.Context, in *article_protos.GetDerivationTreeRequest) (*article_protos.GetDerivationTreeResponse, error) {
+	if in.ArticleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "article_id is required")
+	}
+	if in.MaxDepth < 0 || in.MaxDepth > maxDerivationDepth {
+		return nil, status.Errorf(codes.InvalidArgument, "max_depth must be between 0 and %d", maxDerivationDepth)
+	}
+	maxDepth := int(in.MaxDepth)
+	if maxDepth == 0 {
+		maxDepth = defaultDerivationDepth
+	}
+
+	// one extra row tells us whether the tree was cut by the node limit
+	var rows []models.LineageArticle
+	if err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT articles.*, 0 AS depth
+			FROM articles
+			WHERE id = ? AND status = ?
+			UNION ALL
+			SELECT child.*, tree.depth + 1
+			FROM articles AS child
+			JOIN tree ON child.original_article_id = tree.id
+			WHERE tree.depth < ? AND child.status = ?
+		)
+		SELECT * FROM tree ORDER BY depth, created_at LIMIT ?`,
+		in.ArticleId, models.ArticlePublished, maxDepth, models.ArticlePublished, maxDerivationNodes+1).
+		Scan(&rows).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch derivation tree: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
+	truncated := len(rows) > maxDerivationNodes
+	if truncated {
+		rows = rows[:maxDerivationNodes]
+	}
+
+	entities := make([]*article_protos.ArticleEntity, len(rows))
+	nodes := make(map[string]*article_protos.DerivationNode, len(rows))
+	for i := range rows {
+		entities[i] = rows[i].Article.ToArticleEntity()
+		nodes[rows[i].Article.ID] = &article_protos.DerivationNode{
+			Article: entities[i],
+			Depth:   int32(rows[i].Depth),
+		}
+	}
+	if err := r.attachReactionCounts(ctx, entities...); err != nil {
+		return nil, err
+	}
+
+	// rows are ordered by depth, so every parent is already in the map when its children are linked
+	for i := 1; i < len(rows); i++ {
+		if parent, ok := nodes[rows[i].Article.OriginalArticleID]; ok {
+			parent.Children = append(parent.Children, nodes[rows[i].Article.ID])
+		}
+	}
+
+	return &article_protos.GetDerivationTreeResponse{
+		Root:      nodes[rows[0].Article.ID],
+		Truncated: truncated,
+	}, nil
+}
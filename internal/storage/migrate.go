@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the pg_advisory_lock key serialising migrations between replicas
+const migrationLockID int64 = 0x6d6d5f6172746963
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus describes one known or applied migration
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator applies the SQL migrations embedded in the binary and records them in schema_migrations
+type Migrator struct {
+	db         *gorm.DB
+	migrations []migration
+	dryRun     bool
+}
+
+// NewMigrator creates a Migrator over the embedded migrations
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	entries, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*migration)
+	for _, path := range entries {
+		name := path[len("migrations/"):]
+		match := migrationFileName.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		}
+		if m.name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down scripts", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// DryRun returns a copy of the migrator that reports what Up, Down and To would do without
+// touching the schema
+func (m *Migrator) DryRun() *Migrator {
+	dry := *m
+	dry.dryRun = true
+	return &dry
+}
+
+// Latest returns the newest version known to the binary
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].version
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) ([]MigrationStatus, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the given number of most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) ([]MigrationStatus, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive")
+	}
+	return m.migrate(ctx, func(applied []appliedMigration) int64 {
+		if steps >= len(applied) {
+			return 0
+		}
+		return applied[len(applied)-1-steps].Version
+	})
+}
+
+// To applies or reverts migrations until the schema is exactly at the target version
+func (m *Migrator) To(ctx context.Context, target int64) ([]MigrationStatus, error) {
+	if target < 0 || (target > 0 && m.find(target) == nil) {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+	return m.migrate(ctx, func([]appliedMigration) int64 { return target })
+}
+
+// Status lists the embedded migrations together with anything applied by a newer binary
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int64]appliedMigration, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		s := MigrationStatus{Version: mg.version, Name: mg.name}
+		if a, ok := appliedAt[mg.version]; ok {
+			s.Applied, s.AppliedAt = true, &a.AppliedAt
+			delete(appliedAt, mg.version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, a := range applied {
+		if _, unknown := appliedAt[a.Version]; unknown {
+			statuses = append(statuses, MigrationStatus{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: &a.AppliedAt})
+		}
+	}
+	return statuses, nil
+}
+
+// CheckSchema fails when any embedded migration has not been applied yet, migrations
+// applied by a newer binary are tolerated so rolling deploys keep working
+func (m *Migrator) CheckSchema(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, s := range statuses {
+		if !s.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema has %d pending migration(s) up to version %d, run the migrate up command first", pending, m.Latest())
+	}
+	return nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, resolveTarget func([]appliedMigration) int64) ([]MigrationStatus, error) {
+	var done []MigrationStatus
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		target := resolveTarget(applied)
+
+		isApplied := make(map[int64]bool, len(applied))
+		for _, a := range applied {
+			isApplied[a.Version] = true
+		}
+		for i := len(applied) - 1; i >= 0; i-- {
+			if applied[i].Version > target && m.find(applied[i].Version) == nil {
+				return fmt.Errorf("migration %d_%s is applied but unknown to this binary", applied[i].Version, applied[i].Name)
+			}
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mg := m.migrations[i]
+			if mg.version <= target || !isApplied[mg.version] {
+				continue
+			}
+			if !m.dryRun {
+				if err := revertMigration(conn, mg); err != nil {
+					return err
+				}
+			}
+			done = append(done, MigrationStatus{Version: mg.version, Name: mg.name})
+		}
+		for _, mg := range m.migrations {
+			if mg.version > target || isApplied[mg.version] {
+				continue
+			}
+			status := MigrationStatus{Version: mg.version, Name: mg.name, Applied: true}
+			if !m.dryRun {
+				if err := applyMigration(conn, mg); err != nil {
+					return err
+				}
+				now := time.Now()
+				status.AppliedAt = &now
+			}
+			done = append(done, status)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// ensureTable creates schema_migrations, a dry run leaves a fresh database untouched
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	if m.dryRun {
+		return nil
+	}
+	if err := conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`).Error; err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// withLock runs fn on a single pinned connection holding the migration advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) applied(db *gorm.DB) ([]appliedMigration, error) {
+	if !db.Migrator().HasTable("schema_migrations") {
+		return nil, nil
+	}
+	var applied []appliedMigration
+	if err := db.Table("schema_migrations").Order("version").Find(&applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int64) *migration {
+	for i := range m.migrations {
+		if m.migrations[i].version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func applyMigration(conn *gorm.DB, mg migration) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mg.up).Error; err != nil {
+			return err
+		}
+		if hook, ok := migrationHooks[mg.version]; ok {
+			if err := hook(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", mg.version, mg.name).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", mg.version, mg.name, err)
+	}
+	return nil
+}
+
+func revertMigration(conn *gorm.DB, mg migration) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mg.down).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", mg.version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revert migration %d_%s: %w", mg.version, mg.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range migrations {
+		if i > 0 && m.version <= migrations[i-1].version {
+			t.Errorf("migration %d_%s is out of order", m.version, m.name)
+		}
+	}
+
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.version] = true
+	}
+	for version := range migrationHooks {
+		if !known[version] {
+			t.Errorf("migration hook registered for unknown version %d", version)
+		}
+	}
+}
+
+func TestFrozenBackfills(t *testing.T) {
+	if got := renderPlain0004("a <b>\r\nline\n\n\n\nnext"); got != "<p>a &lt;b&gt;<br>line</p>\n<p>next</p>\n" {
+		t.Errorf("renderPlain0004 = %q", got)
+	}
+	if got := strings.Fields(plainText0004("<p>one<br>two</p>")); len(got) != 2 {
+		t.Errorf("plainText0004 words = %q", got)
+	}
+	if got := slug0005("Oʻzbekiston — Тошкент!"); got != "ozbekiston-toshkent" {
+		t.Errorf("slug0005 = %q", got)
+	}
+	if got := slug0005("!!!"); got != "article" {
+		t.Errorf("slug0005 of punctuation = %q", got)
+	}
+	if got := withSuffix0005("top", []string{"top", "top-2"}); got != "top-3" {
+		t.Errorf("withSuffix0005 = %q", got)
+	}
+}
@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// The hooks below are frozen: they carry their own copy of the rendering and slug rules as they
+// were when their migration was written and touch tables by name, never through models. Later
+// changes to the live code must not change what a migration does on a database that runs it late,
+// re-deriving columns with the current rules is the reindex command's job
+
+// migrationHooks are data backfills that need Go code, they run after the up script of their
+// version inside the same transaction. Versions are append-only like the scripts
+var migrationHooks = map[int64]func(tx *gorm.DB) error{
+	4: backfillRenderedContent0004,
+	5: backfillSlugs0005,
+}
+
+const migrationBatchSize = 500
+
+// backfillRenderedContent0004 renders content_html and the reading metadata of the articles stored
+// before 0004. Only plain text existed then, 0004 added content_format with that default
+func backfillRenderedContent0004(tx *gorm.DB) error {
+	type row struct {
+		ID      string
+		Content string
+	}
+	lastID := ""
+	for {
+		var rows []row
+		query := tx.Table("articles").Select("id", "content").Where("content_html = ''").Order("id").Limit(migrationBatchSize)
+		if lastID != "" {
+			query = query.Where("id > ?", lastID)
+		}
+		if err := query.Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, r := range rows {
+			rendered := renderPlain0004(r.Content)
+			words := strings.Fields(plainText0004(rendered))
+			readingTime := 0
+			if len(words) > 0 {
+				readingTime = int(math.Ceil(float64(len(words)) / 200))
+			}
+			if err := tx.Table("articles").Where("id = ?", r.ID).UpdateColumns(map[string]any{
+				"content_html":         rendered,
+				"word_count":           len(words),
+				"reading_time_minutes": readingTime,
+				"excerpt":              excerpt0004(words),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if len(rows) < migrationBatchSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+// renderPlain0004 escapes text and keeps its paragraph and line structure. The output only holds
+// <p> and <br> around escaped text, which the sanitiser of the time left untouched
+func renderPlain0004(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	var sb strings.Builder
+	for _, paragraph := range strings.Split(source, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i := range lines {
+			lines[i] = html.EscapeString(lines[i])
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(strings.Join(lines, "<br>"))
+		sb.WriteString("</p>\n")
+	}
+	return sb.String()
+}
+
+func plainText0004(fragment string) string {
+	var sb strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch tokenizer.Next() {
+		case xhtml.ErrorToken:
+			return sb.String()
+		case xhtml.TextToken:
+			sb.Write(tokenizer.Text())
+		case xhtml.StartTagToken, xhtml.EndTagToken, xhtml.SelfClosingTagToken:
+			sb.WriteByte(' ')
+		}
+	}
+}
+
+func excerpt0004(words []string) string {
+	const excerptLength = 280
+	var sb strings.Builder
+	length := 0
+	for i, word := range words {
+		wordLength := utf8.RuneCountInString(word)
+		if i > 0 {
+			wordLength++
+		}
+		if length+wordLength > excerptLength {
+			if length == 0 {
+				return string([]rune(word)[:excerptLength]) + "…"
+			}
+			return sb.String() + "…"
+		}
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(word)
+		length += wordLength
+	}
+	return sb.String()
+}
+
+// backfillSlugs0005 assigns slugs to the articles stored before 0005, oldest first so earlier
+// articles keep the unsuffixed slug. It records no base, 0012 derives the bases of these rows
+func backfillSlugs0005(tx *gorm.DB) error {
+	type row struct {
+		ID    string
+		Title string
+	}
+	for {
+		var rows []row
+		if err := tx.Table("articles").Select("id", "title").Where("slug IS NULL").
+			Order("created_at, id").Limit(migrationBatchSize).Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, r := range rows {
+			base := slug0005(r.Title)
+			var taken []string
+			if err := tx.Table("article_slugs").Where("slug = ? OR slug LIKE ?", base, base+"-%").
+				Pluck("slug", &taken).Error; err != nil {
+				return err
+			}
+			s := withSuffix0005(base, taken)
+			if err := tx.Exec("INSERT INTO article_slugs (slug, article_id, created_at) VALUES (?, ?, now())", s, r.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Table("articles").Where("id = ?", r.ID).UpdateColumn("slug", s).Error; err != nil {
+				return err
+			}
+		}
+		if len(rows) < migrationBatchSize {
+			return nil
+		}
+	}
+}
+
+var cyrillic0005 = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "j",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "x", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "", 'ы': "i", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h",
+}
+
+var apostrophes0005 = map[rune]bool{
+	'\'': true, 'ʻ': true, 'ʼ': true, '‘': true, '’': true, '`': true, 'ʹ': true,
+}
+
+// slug0005 builds a lowercase, hyphen separated ASCII slug of at most 80 bytes from a title
+func slug0005(title string) string {
+	var sb strings.Builder
+	pendingHyphen := false
+	write := func(s string) {
+		if pendingHyphen && sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		pendingHyphen = false
+		sb.WriteString(s)
+	}
+	for _, r := range strings.ToLower(title) {
+		if latin, ok := cyrillic0005[r]; ok {
+			write(latin)
+			continue
+		}
+		if apostrophes0005[r] || unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		word := ""
+		for _, d := range norm.NFD.String(string(r)) {
+			if d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)) {
+				word += string(d)
+			}
+		}
+		if word == "" {
+			pendingHyphen = true
+			continue
+		}
+		write(word)
+	}
+
+	s := sb.String()
+	if len(s) > 80 {
+		s = strings.TrimRight(s[:80], "-")
+	}
+	if s == "" {
+		return "article"
+	}
+	return s
+}
+
+func withSuffix0005(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	if !used[base] {
+		return base
+	}
+	for i := 2; ; i++ {
+		candidate := base + "-" + strconv.Itoa(i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
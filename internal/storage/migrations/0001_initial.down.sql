@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pictures;
+DROP TABLE IF EXISTS article_likes;
+DROP TABLE IF EXISTS articles;
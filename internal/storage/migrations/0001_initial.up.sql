@@ -0,0 +1,23 @@
+-- Baseline schema as previously created by AutoMigrate, IF NOT EXISTS lets existing databases adopt it
+CREATE TABLE IF NOT EXISTS articles (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    original_article_id uuid DEFAULT NULL,
+    title text NOT NULL,
+    content text NOT NULL,
+    created_at timestamptz,
+    likes_count bigint NOT NULL DEFAULT 0,
+    version bigint DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS article_likes (
+    user_id uuid NOT NULL,
+    article_id uuid NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, article_id)
+);
+
+CREATE TABLE IF NOT EXISTS pictures (
+    file_name text NOT NULL,
+    article_id text NOT NULL
+);
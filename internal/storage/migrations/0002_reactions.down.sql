@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS article_likes (
+    user_id uuid NOT NULL,
+    article_id uuid NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, article_id)
+);
+
+INSERT INTO article_likes (user_id, article_id, created_at)
+SELECT user_id, article_id, created_at
+FROM article_reactions
+WHERE reaction_type = 'like'
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS article_reaction_counts;
+DROP TABLE IF EXISTS article_reactions;
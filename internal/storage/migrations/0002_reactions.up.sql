@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS article_reactions (
+    user_id uuid NOT NULL,
+    article_id uuid NOT NULL,
+    reaction_type varchar(32) NOT NULL,
+    count bigint NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (user_id, article_id, reaction_type)
+);
+CREATE INDEX IF NOT EXISTS idx_article_reactions_article_type_created
+    ON article_reactions (article_id, reaction_type, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_article_reactions_user_type_created
+    ON article_reactions (user_id, reaction_type, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS article_reaction_counts (
+    article_id uuid NOT NULL,
+    reaction_type varchar(32) NOT NULL,
+    total bigint NOT NULL DEFAULT 0,
+    reactors bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (article_id, reaction_type)
+);
+
+-- legacy likes become "like" reactions
+INSERT INTO article_reactions (user_id, article_id, reaction_type, count, created_at, updated_at)
+SELECT user_id, article_id, 'like', 1, created_at, created_at
+FROM article_likes
+ON CONFLICT DO NOTHING;
+
+INSERT INTO article_reaction_counts (article_id, reaction_type, total, reactors)
+SELECT article_id, reaction_type, SUM(count), COUNT(*)
+FROM article_reactions
+GROUP BY article_id, reaction_type
+ON CONFLICT (article_id, reaction_type) DO UPDATE
+SET total = EXCLUDED.total, reactors = EXCLUDED.reactors;
+
+DROP TABLE article_likes;
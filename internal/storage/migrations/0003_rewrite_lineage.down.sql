@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_articles_original_article_id;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS attribution,
+    DROP COLUMN IF EXISTS license,
+    DROP COLUMN IF EXISTS rewrite_policy,
+    DROP COLUMN IF EXISTS rewrites_count;
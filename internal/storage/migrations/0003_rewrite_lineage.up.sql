@@ -0,0 +1,17 @@
+ALTER TABLE articles
+    ADD COLUMN IF NOT EXISTS rewrites_count bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rewrite_policy varchar(32) NOT NULL DEFAULT 'allowed',
+    ADD COLUMN IF NOT EXISTS license varchar(32) NOT NULL DEFAULT 'all_rights_reserved',
+    ADD COLUMN IF NOT EXISTS attribution text NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_articles_original_article_id ON articles (original_article_id);
+
+UPDATE articles AS a
+SET rewrites_count = c.total
+FROM (
+    SELECT original_article_id, COUNT(*) AS total
+    FROM articles
+    WHERE original_article_id IS NOT NULL
+    GROUP BY original_article_id
+) AS c
+WHERE a.id = c.original_article_id;
@@ -0,0 +1,7 @@
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS excerpt,
+    DROP COLUMN IF EXISTS reading_time_minutes,
+    DROP COLUMN IF EXISTS word_count,
+    DROP COLUMN IF EXISTS content_html,
+    DROP COLUMN IF EXISTS content_format;
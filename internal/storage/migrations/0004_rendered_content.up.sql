@@ -0,0 +1,10 @@
+-- content_html and the reading metadata are rendered in Go right after this script runs
+ALTER TABLE articles
+    ADD COLUMN IF NOT EXISTS content_format varchar(16) NOT NULL DEFAULT 'plain',
+    ADD COLUMN IF NOT EXISTS content_html text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS word_count bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reading_time_minutes bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS excerpt text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS updated_at timestamptz;
+
+UPDATE articles SET updated_at = created_at WHERE updated_at IS NULL;
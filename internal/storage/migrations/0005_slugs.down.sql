@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS article_slugs;
+DROP INDEX IF EXISTS idx_articles_slug;
+ALTER TABLE articles DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,10 @@
+-- slugs of existing articles are assigned in Go right after this script runs
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS slug varchar(128) DEFAULT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_slug ON articles (slug);
+
+CREATE TABLE IF NOT EXISTS article_slugs (
+    slug varchar(128) PRIMARY KEY,
+    article_id uuid NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_article_slugs_article_id ON article_slugs (article_id);
@@ -0,0 +1,2 @@
+-- content_revision only moves on edits, unlike version which is also bumped by likes
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content_revision bigint NOT NULL DEFAULT 1;
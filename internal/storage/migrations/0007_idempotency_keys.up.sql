@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key varchar(255) NOT NULL,
+    method varchar(255) NOT NULL,
+    fingerprint char(64) NOT NULL,
+    state varchar(16) NOT NULL,
+    response_type text NOT NULL DEFAULT '',
+    response bytea,
+    created_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY (key, method)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_pictures_file_article;
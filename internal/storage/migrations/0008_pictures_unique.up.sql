@@ -0,0 +1,8 @@
+-- imports skip pictures that already exist, which needs a key to conflict on
+DELETE FROM pictures AS a
+USING pictures AS b
+WHERE a.ctid > b.ctid
+    AND a.file_name = b.file_name
+    AND a.article_id = b.article_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pictures_file_article ON pictures (file_name, article_id);
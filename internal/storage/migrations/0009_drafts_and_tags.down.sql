@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_articles_tags;
+DROP INDEX IF EXISTS idx_articles_status_created;
+ALTER TABLE articles DROP COLUMN IF EXISTS tags;
+ALTER TABLE articles DROP COLUMN IF EXISTS status;
@@ -0,0 +1,5 @@
+-- existing articles were all public, so they start out published
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'published';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS tags jsonb NOT NULL DEFAULT '[]';
+CREATE INDEX IF NOT EXISTS idx_articles_status_created ON articles (status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_articles_tags ON articles USING GIN (tags);
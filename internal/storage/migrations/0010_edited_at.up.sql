@@ -0,0 +1,4 @@
+-- updated_at also moves on likes, edited_at only on content edits; the best guess for existing rows is updated_at
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS edited_at timestamptz;
+UPDATE articles SET edited_at = updated_at WHERE edited_at IS NULL;
+ALTER TABLE articles ALTER COLUMN edited_at SET NOT NULL;
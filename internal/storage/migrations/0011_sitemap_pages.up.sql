@@ -0,0 +1,9 @@
+-- rendered sitemap files, the background job rewrites a page only when the articles it lists change
+CREATE TABLE IF NOT EXISTS sitemap_pages (
+    page integer PRIMARY KEY,
+    url_count integer NOT NULL,
+    fingerprint char(64) NOT NULL,
+    last_modified timestamptz NOT NULL,
+    body bytea NOT NULL,
+    generated_at timestamptz NOT NULL
+);
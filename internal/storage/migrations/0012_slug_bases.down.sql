@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_article_slugs_base;
+ALTER TABLE article_slugs DROP COLUMN IF EXISTS base;
@@ -0,0 +1,14 @@
+-- the base a slug was generated from tells a collision suffix ("top" taken, so "top-2") apart from
+-- a title that ends in a number ("Top 2"). For existing rows a numeric suffix is taken as a
+-- collision only when another article already held the unsuffixed slug
+ALTER TABLE article_slugs ADD COLUMN IF NOT EXISTS base varchar(128);
+UPDATE article_slugs AS s SET base = substring(s.slug FROM '^(.*)-[0-9]+$')
+WHERE s.base IS NULL AND EXISTS (
+    SELECT 1 FROM article_slugs AS o
+    WHERE o.slug = substring(s.slug FROM '^(.*)-[0-9]+$')
+      AND o.article_id <> s.article_id
+      AND o.created_at <= s.created_at
+);
+UPDATE article_slugs SET base = slug WHERE base IS NULL;
+ALTER TABLE article_slugs ALTER COLUMN base SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_article_slugs_base ON article_slugs (base);
@@ -0,0 +1,6 @@
+-- the same key may now exist for several callers, the rows are a replay cache and are dropped
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS token;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS caller;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key, method);
@@ -0,0 +1,6 @@
+-- keys are scoped to the caller, so one user cannot replay or block another user's key, and every
+-- claim carries a token so a request whose claim was taken over cannot complete or release it
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS caller varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS token varchar(36) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (caller, key, method);
@@ -0,0 +1,9 @@
+UPDATE articles AS a
+SET rewrites_count = c.total
+FROM (
+    SELECT articles.id, COUNT(rewrites.id) AS total
+    FROM articles
+    LEFT JOIN articles AS rewrites ON rewrites.original_article_id = articles.id
+    GROUP BY articles.id
+) AS c
+WHERE a.id = c.id AND a.rewrites_count <> c.total;
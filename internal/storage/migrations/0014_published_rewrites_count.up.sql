@@ -0,0 +1,12 @@
+-- rewrites_count counts the rewrites ListArticleRewrites lists, the published ones. 0003 counted
+-- every rewrite, which was the same thing until drafts arrived in 0009
+UPDATE articles AS a
+SET rewrites_count = c.total
+FROM (
+    SELECT articles.id, COUNT(rewrites.id) AS total
+    FROM articles
+    LEFT JOIN articles AS rewrites
+        ON rewrites.original_article_id = articles.id AND rewrites.status = 'published'
+    GROUP BY articles.id
+) AS c
+WHERE a.id = c.id AND a.rewrites_count <> c.total;
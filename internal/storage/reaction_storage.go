@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errConcurrentReaction = errors.New("concurrent reaction, retrying")
+
+// AddReaction applies a reaction, accumulating up to the per-type cap for repeatable reactions like claps
+func (r *articleRepository) AddReaction(ctx context.Context, in *article_protos.AddReactionRequest) (*article_protos.ReactionResponse, error) {
+	if in.UserId == "" || in.ArticleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and article_id are required")
+	}
+	reactionType, ok := models.ReactionTypeFromProto(in.ReactionType)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown reaction_type")
+	}
+	count := int(in.Count)
+	if count <= 0 {
+		count = 1
+	}
+	return r.addReaction(ctx, in.UserId, in.ArticleId, reactionType, count)
+}
+
+// RemoveReaction withdraws all of a user's reactions of the given type
+func (r *articleRepository) RemoveReaction(ctx context.Context, in *article_protos.RemoveReactionRequest) (*article_protos.ReactionResponse, error) {
+	if in.UserId == "" || in.ArticleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and article_id are required")
+	}
+	reactionType, ok := models.ReactionTypeFromProto(in.ReactionType)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown reaction_type")
+	}
+	return r.removeReaction(ctx, in.UserId, in.ArticleId, reactionType)
+}
+
+func (r *articleRepository) addReaction(ctx context.Context, userID, articleID, reactionType string, count int) (*article_protos.ReactionResponse, error) {
+	limit := models.ReactionCap(reactionType)
+	var userCount int
+	var err error
+
+	const maxRetries = 3 // TODO: get this value from config
+	for i := 0; i < maxRetries; i++ {
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			articleStatus, err := lockArticle(tx, articleID)
+			if err != nil {
+				return err
+			}
+			if articleStatus != models.ArticlePublished {
+				// a draft is private to its author, answer as if it did not exist
+				return status.Error(codes.NotFound, "article not found")
+			}
+
+			var existing models.ArticleReaction
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ? AND article_id = ? AND reaction_type = ?", userID, articleID, reactionType).
+				Take(&existing).Error
+			isNew := errors.Is(err, gorm.ErrRecordNotFound)
+			if err != nil && !isNew {
+				return err
+			}
+			if existing.Count >= limit {
+				if limit == 1 {
+					return status.Errorf(codes.AlreadyExists, "user has already reacted with %s", reactionType)
+				}
+				return status.Errorf(codes.FailedPrecondition, "%s limit of %d reached", reactionType, limit)
+			}
+
+			delta := min(count, limit-existing.Count)
+			reactors := 0
+			if isNew {
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ArticleReaction{
+					UserID:       userID,
+					ArticleID:    articleID,
+					ReactionType: reactionType,
+					Count:        delta,
+				})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return errConcurrentReaction
+				}
+				reactors = 1
+			} else {
+				if err := tx.Model(&models.ArticleReaction{}).
+					Where("user_id = ? AND article_id = ? AND reaction_type = ?", userID, articleID, reactionType).
+					Update("count", gorm.Expr("count + ?", delta)).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := bumpReactionCount(tx, articleID, reactionType, delta, reactors); err != nil {
+				return err
+			}
+			if reactionType == models.ReactionLike {
+				if err := bumpLikesCount(tx, articleID, 1); err != nil {
+					return err
+				}
+			}
+			userCount = existing.Count + delta
+			return nil
+		})
+		if !errors.Is(err, errConcurrentReaction) {
+			break
+		}
+	}
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Aborted, "failed to add reaction: %v", err)
+	}
+
+	return r.reactionResponse(ctx, articleID, userCount)
+}
+
+func (r *articleRepository) removeReaction(ctx context.Context, userID, articleID, reactionType string) (*article_protos.ReactionResponse, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockArticle(tx, articleID); err != nil {
+			return err
+		}
+
+		var existing models.ArticleReaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND article_id = ? AND reaction_type = ?", userID, articleID, reactionType).
+			Take(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.Error(codes.NotFound, "reaction not found")
+			}
+			return err
+		}
+
+		if err := tx.Where("user_id = ? AND article_id = ? AND reaction_type = ?", userID, articleID, reactionType).
+			Delete(&models.ArticleReaction{}).Error; err != nil {
+			return err
+		}
+		if err := bumpReactionCount(tx, articleID, reactionType, -existing.Count, -1); err != nil {
+			return err
+		}
+		if reactionType == models.ReactionLike {
+			return bumpLikesCount(tx, articleID, -1)
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Aborted, "failed to remove reaction: %v", err)
+	}
+
+	return r.reactionResponse(ctx, articleID, 0)
+}
+
+func (r *articleRepository) reactionResponse(ctx context.Context, articleID string, userCount int) (*article_protos.ReactionResponse, error) {
+	counts, err := r.reactionCounts(ctx, []string{articleID})
+	if err != nil {
+		return nil, err
+	}
+	return &article_protos.ReactionResponse{
+		Reactions: counts[articleID],
+		UserCount: int32(userCount),
+	}, nil
+}
+
+// reactionCounts loads per-type aggregates for a set of articles keyed by article id
+func (r *articleRepository) reactionCounts(ctx context.Context, articleIDs []string) (map[string][]*article_protos.ReactionCount, error) {
+	result := make(map[string][]*article_protos.ReactionCount, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return result, nil
+	}
+
+	var counts []models.ArticleReactionCount
+	if err := r.db.WithContext(ctx).
+		Where("article_id IN ? AND reactors > 0", articleIDs).
+		Order("reaction_type").
+		Find(&counts).Error; err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch reaction counts: %v", err)
+	}
+	for i := range counts {
+		result[counts[i].ArticleID] = append(result[counts[i].ArticleID], counts[i].ToReactionCount())
+	}
+	return result, nil
+}
+
+// attachReactionCounts fills the Reactions field of each article entity
+func (r *articleRepository) attachReactionCounts(ctx context.Context, articles ...*article_protos.ArticleEntity) error {
+	ids := make([]string, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].Id
+	}
+	counts, err := r.reactionCounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range articles {
+		articles[i].Reactions = counts[articles[i].Id]
+	}
+	return nil
+}
+
+func (r *articleRepository) ensureArticleExists(ctx context.Context, articleID string) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Article{}).Where("id = ?", articleID).Count(&count).Error; err != nil {
+		return status.Errorf(codes.Internal, "failed to verify article: %v", err)
+	}
+	if count == 0 {
+		return status.Error(codes.NotFound, "article not found")
+	}
+	return nil
+}
+
+// lockArticles takes the row locks of several articles in id order, the order reconcile locks a
+// batch in, so writers touching two articles cannot deadlock against it
+func lockArticles(tx *gorm.DB, articleIDs ...string) error {
+	return tx.Exec(`SELECT id FROM articles WHERE id IN ? ORDER BY id FOR UPDATE`, articleIDs).Error
+}
+
+// lockArticle takes the article row lock and returns the article's status. Every writer of the
+// reaction counters takes this lock before touching any counter row, reconcile included, so they
+// serialise per article in one order and cannot deadlock or overwrite each other's deltas
+func lockArticle(tx *gorm.DB, articleID string) (string, error) {
+	var article models.Article
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").
+		Where("id = ?", articleID).Take(&article).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", status.Error(codes.NotFound, "article not found")
+		}
+		return "", err
+	}
+	return article.Status, nil
+}
+
+func bumpReactionCount(tx *gorm.DB, articleID, reactionType string, total, reactors int) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "article_id"}, {Name: "reaction_type"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"total":    gorm.Expr("article_reaction_counts.total + EXCLUDED.total"),
+			"reactors": gorm.Expr("article_reaction_counts.reactors + EXCLUDED.reactors"),
+		}),
+	}).Create(&models.ArticleReactionCount{
+		ArticleID:    articleID,
+		ReactionType: reactionType,
+		Total:        total,
+		Reactors:     reactors,
+	}).Error
+}
+
+// bumpLikesCount keeps the denormalised Article.LikesCount in step with "like" reactions
+func bumpLikesCount(tx *gorm.DB, articleID string, delta int) error {
+	return tx.Model(&models.Article{}).
+		Where("id = ?", articleID).
+		Updates(map[string]any{
+			"likes_count": gorm.Expr("likes_count + ?", delta),
+			"version":     gorm.Expr("version + 1"),
+		}).Error
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// expectLockArticle expects the article row lock every counter writer takes first
+func expectLockArticle(mock sqlmock.Sqlmock, articleID, articleStatus string) {
+	mock.ExpectQuery(`SELECT "id","status" FROM "articles" WHERE id = \$1 .*FOR UPDATE`).
+		WithArgs(articleID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(articleID, articleStatus))
+}
+
+func expectReactionRow(mock sqlmock.Sqlmock, count int) {
+	rows := sqlmock.NewRows([]string{"user_id", "article_id", "reaction_type", "count"})
+	if count > 0 {
+		rows.AddRow("u-1", "a-1", "x", count)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "article_reactions" WHERE .* FOR UPDATE`).WillReturnRows(rows)
+}
+
+func expectReactionResponse(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT \* FROM "article_reaction_counts"`).
+		WillReturnRows(sqlmock.NewRows([]string{"article_id", "reaction_type", "total", "reactors"}))
+}
+
+func TestLikeArticleLocksArticleBeforeCounters(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 0)
+	mock.ExpectExec(`INSERT INTO "article_reactions"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "article_reaction_counts"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "articles" SET "likes_count"=likes_count \+ \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectReactionResponse(mock)
+
+	if _, err := repo.LikeArticle(context.Background(), &article_protos.LikeArticleRequest{UserId: "u-1", ArticleId: "a-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRemoveReactionLocksArticleBeforeCounters(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticleDraft)
+	expectReactionRow(mock, 7)
+	mock.ExpectExec(`DELETE FROM "article_reactions"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "article_reaction_counts"`).
+		WithArgs("a-1", models.ReactionClap, -7, -1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectReactionResponse(mock)
+
+	_, err := repo.RemoveReaction(context.Background(), &article_protos.RemoveReactionRequest{
+		UserId: "u-1", ArticleId: "a-1", ReactionType: article_protos.ReactionType_REACTION_TYPE_CLAP,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddReactionAccumulatesClaps(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 10)
+	mock.ExpectExec(`UPDATE "article_reactions" SET "count"=count \+ \$1`).
+		WithArgs(5, sqlmock.AnyArg(), "u-1", "a-1", models.ReactionClap).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "article_reaction_counts"`).
+		WithArgs("a-1", models.ReactionClap, 5, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectReactionResponse(mock)
+
+	resp, err := repo.AddReaction(context.Background(), &article_protos.AddReactionRequest{
+		UserId: "u-1", ArticleId: "a-1", ReactionType: article_protos.ReactionType_REACTION_TYPE_CLAP, Count: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.UserCount != 15 {
+		t.Errorf("user count %d, want 15", resp.UserCount)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddReactionCapsClaps(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	// 48 of 50 claps used, asking for 10 adds only the remaining 2
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 48)
+	mock.ExpectExec(`UPDATE "article_reactions" SET "count"=count \+ \$1`).
+		WithArgs(2, sqlmock.AnyArg(), "u-1", "a-1", models.ReactionClap).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "article_reaction_counts"`).
+		WithArgs("a-1", models.ReactionClap, 2, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectReactionResponse(mock)
+
+	req := &article_protos.AddReactionRequest{
+		UserId: "u-1", ArticleId: "a-1", ReactionType: article_protos.ReactionType_REACTION_TYPE_CLAP, Count: 10,
+	}
+	resp, err := repo.AddReaction(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.UserCount != 50 {
+		t.Errorf("user count %d, want the cap of 50", resp.UserCount)
+	}
+
+	// at the cap nothing is written
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 50)
+	mock.ExpectRollback()
+	if _, err := repo.AddReaction(context.Background(), req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("clapping past the cap: got %v, want FailedPrecondition", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddReactionRetriesConcurrentInsert(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	// another request inserted the reaction between our read and insert
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 0)
+	mock.ExpectExec(`INSERT INTO "article_reactions" .* ON CONFLICT DO NOTHING`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	// the retry sees the row and adds to it
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 3)
+	mock.ExpectExec(`UPDATE "article_reactions" SET "count"=count \+ \$1`).
+		WithArgs(1, sqlmock.AnyArg(), "u-1", "a-1", models.ReactionClap).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "article_reaction_counts"`).
+		WithArgs("a-1", models.ReactionClap, 1, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectReactionResponse(mock)
+
+	resp, err := repo.AddReaction(context.Background(), &article_protos.AddReactionRequest{
+		UserId: "u-1", ArticleId: "a-1", ReactionType: article_protos.ReactionType_REACTION_TYPE_CLAP,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.UserCount != 4 {
+		t.Errorf("user count %d, want 4", resp.UserCount)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRemoveLikeDecrementsCounters(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	expectLockArticle(mock, "a-1", models.ArticlePublished)
+	expectReactionRow(mock, 1)
+	mock.ExpectExec(`DELETE FROM "article_reactions"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "article_reaction_counts"`).
+		WithArgs("a-1", models.ReactionLike, -1, -1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "articles" SET "likes_count"=likes_count \+ \$1`).
+		WithArgs(-1, sqlmock.AnyArg(), "a-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectReactionResponse(mock)
+
+	if _, err := repo.UnlikeArticle(context.Background(), &article_protos.UnlikeArticleRequest{UserId: "u-1", ArticleId: "a-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockRepository(t *testing.T) (*articleRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &articleRepository{db: db}, mock
+}
+
+// expectReconcileBatch expects one batch transaction, the articles are locked before anything is counted
+func expectReconcileBatch(mock sqlmock.Sqlmock, likesFixed, rewritesFixed, countsFixed int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM articles WHERE id IN \(\$1,\$2\) ORDER BY id FOR UPDATE`).
+		WithArgs("a-1", "a-2").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE articles AS a\s+SET likes_count = c.total`).
+		WillReturnResult(sqlmock.NewResult(0, likesFixed))
+	mock.ExpectExec(`UPDATE articles AS a\s+SET rewrites_count = c.total.*rewrites.status = \$1`).
+		WithArgs(models.ArticlePublished, "a-1", "a-2").WillReturnResult(sqlmock.NewResult(0, rewritesFixed))
+	mock.ExpectExec(`INSERT INTO article_reaction_counts`).
+		WillReturnResult(sqlmock.NewResult(0, countsFixed))
+	mock.ExpectExec(`UPDATE article_reaction_counts AS c`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectBatchIDs(mock sqlmock.Sqlmock, ids ...string) {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT "id" FROM "articles"`).WillReturnRows(rows)
+}
+
+func TestReconcileLikesLocksBeforeCounting(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	expectBatchIDs(mock, "a-1", "a-2")
+	expectReconcileBatch(mock, 1, 1, 2)
+	mock.ExpectCommit()
+	expectBatchIDs(mock)
+
+	resp, err := repo.ReconcileLikes(context.Background(), &article_protos.ReconcileLikesRequest{BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ArticlesScanned != 2 || resp.ArticlesCorrected != 1 || resp.RewritesCountsCorrected != 1 || resp.ReactionCountsCorrected != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReconcileLikesDryRunRollsBack(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	expectBatchIDs(mock, "a-1", "a-2")
+	expectReconcileBatch(mock, 2, 0, 1)
+	mock.ExpectRollback()
+	expectBatchIDs(mock)
+
+	resp, err := repo.ReconcileLikes(context.Background(), &article_protos.ReconcileLikesRequest{BatchSize: 2, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ArticlesCorrected != 2 || resp.ReactionCountsCorrected != 1 {
+		t.Errorf("a dry run should still report what it would fix, got %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReconcileLikesRejectsNegativeBatch(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	if _, err := repo.ReconcileLikes(context.Background(), &article_protos.ReconcileLikesRequest{BatchSize: -1}); err == nil {
+		t.Error("expected an error for a negative batch size")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
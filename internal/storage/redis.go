@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+)
+
+// NewRedis creates a Redis client, it connects lazily so a Redis outage does not block startup
+func NewRedis(cfg *config.Config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+}
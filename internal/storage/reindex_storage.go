@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/mm_article_service/internal/content"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ReindexArticles re-renders the derived content listings and search read (html, excerpt, reading
+// metadata) for every article and assigns slugs to articles missing one. Rows are only written when
+// something changed and updated_at is left alone, a dry run only counts
+func (r *articleRepository) ReindexArticles(ctx context.Context, batchSize int, dryRun bool) (*models.ReindexResult, error) {
+	if batchSize <= 0 {
+		batchSize = defaultReconcileBatchSize
+	}
+
+	result := &models.ReindexResult{DryRun: dryRun}
+	lastID := ""
+	for {
+		var articles []models.Article
+		query := r.db.WithContext(ctx).
+			Select("id", "title", "slug", "content", "content_format", "content_html", "word_count", "reading_time_minutes", "excerpt").
+			Order("id").Limit(batchSize)
+		if lastID != "" {
+			query = query.Where("id > ?", lastID)
+		}
+		if err := query.Find(&articles).Error; err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fetch article batch: %v", err)
+		}
+
+		for i := range articles {
+			changed, err := rerender(&articles[i])
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to render article %s: %v", articles[i].ID, err)
+			}
+			if changed {
+				result.ContentUpdated++
+				if !dryRun {
+					if err := r.db.WithContext(ctx).Model(&models.Article{}).Where("id = ?", articles[i].ID).
+						UpdateColumns(map[string]any{
+							"content_format":       articles[i].ContentFormat,
+							"content_html":         articles[i].ContentHTML,
+							"word_count":           articles[i].WordCount,
+							"reading_time_minutes": articles[i].ReadingTimeMinutes,
+							"excerpt":              articles[i].Excerpt,
+						}).Error; err != nil {
+						return nil, status.Errorf(codes.Internal, "failed to update article %s: %v", articles[i].ID, err)
+					}
+				}
+			}
+
+			if articles[i].Slug == "" {
+				result.SlugsAssigned++
+				if !dryRun {
+					article := articles[i]
+					err := r.withSlugRetry(ctx, func(tx *gorm.DB) error {
+						s, err := assignSlug(tx, article.ID, article.Title)
+						if err != nil {
+							return err
+						}
+						return tx.Model(&models.Article{}).Where("id = ? AND slug IS NULL", article.ID).UpdateColumn("slug", s).Error
+					})
+					if err != nil {
+						return nil, status.Errorf(codes.Internal, "failed to assign slug to article %s: %v", article.ID, err)
+					}
+				}
+			}
+		}
+
+		result.Scanned += int64(len(articles))
+		if len(articles) < batchSize {
+			return result, nil
+		}
+		lastID = articles[len(articles)-1].ID
+	}
+}
+
+// rerender renders the article body again and reports whether any derived column differs from the stored one
+func rerender(a *models.Article) (bool, error) {
+	format := a.ContentFormat
+	if format == "" {
+		format = content.FormatPlain
+	}
+	rendered, err := content.Render(format, a.Content)
+	if err != nil {
+		return false, err
+	}
+	before := *a
+	a.SetRendered(format, rendered)
+	return before.ContentFormat != a.ContentFormat ||
+		before.ContentHTML != a.ContentHTML ||
+		before.WordCount != a.WordCount ||
+		before.ReadingTimeMinutes != a.ReadingTimeMinutes ||
+		before.Excerpt != a.Excerpt, nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateArticleRejectsStaleRevision(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id","slug","content_revision","status" FROM "articles" WHERE id = \$1 .*FOR UPDATE`).
+		WithArgs("a-1", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "content_revision", "status"}).
+		AddRow("a-1", "title", 3, models.ArticlePublished))
+	mock.ExpectRollback()
+
+	_, err := repo.UpdateArticle(context.Background(), &article_protos.UpdateArticleRequest{
+		ArticleId:        "a-1",
+		Title:            "Title",
+		Content:          "edited on top of revision 2",
+		ContentFormat:    article_protos.ContentFormat_CONTENT_FORMAT_PLAIN,
+		ExpectedRevision: 2,
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("got %v, want FailedPrecondition", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if i, ok := d.(*errdetails.ErrorInfo); ok {
+			info = i
+		}
+	}
+	if info == nil || info.Reason != "STALE_REVISION" || info.Metadata["current_revision"] != "3" {
+		t.Errorf("unexpected details %v", st.Details())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+)
+
+// expectLockRewrite expects a rewrite and its original to be locked in id order
+func expectLockRewrite(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT id FROM articles WHERE id IN \(\$1,\$2\) ORDER BY id FOR UPDATE`).
+		WithArgs("o-1", "r-1").WillReturnResult(sqlmock.NewResult(0, 2))
+}
+
+func TestPublishingADraftRewriteCountsIt(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "original_article_id" FROM "articles" WHERE id = \$1`).
+		WithArgs("r-1", 1).WillReturnRows(sqlmock.NewRows([]string{"original_article_id"}).AddRow("o-1"))
+	expectLockRewrite(mock)
+	mock.ExpectQuery(`SELECT "id","slug","content_revision","status" FROM "articles" WHERE id = \$1 .*FOR UPDATE`).
+		WithArgs("r-1", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "content_revision", "status"}).
+		AddRow("r-1", "title", 1, models.ArticleDraft))
+	mock.ExpectQuery(`SELECT \* FROM "article_slugs"`).
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "article_id", "base"}).AddRow("title", "r-1", "title"))
+	mock.ExpectExec(`UPDATE "articles" SET .*"status"=`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "articles" SET "rewrites_count"=rewrites_count \+ 1`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT \* FROM "articles" WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow("r-1", models.ArticlePublished))
+
+	_, err := repo.UpdateArticle(context.Background(), &article_protos.UpdateArticleRequest{
+		ArticleId:     "r-1",
+		Title:         "Title",
+		Content:       "body",
+		ContentFormat: article_protos.ContentFormat_CONTENT_FORMAT_PLAIN,
+		Status:        article_protos.ArticleStatus_ARTICLE_STATUS_PUBLISHED,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeletingADraftRewriteKeepsTheCount(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id","original_article_id","status" FROM "articles" WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "original_article_id", "status"}).AddRow("r-1", "o-1", models.ArticleDraft))
+	expectLockRewrite(mock)
+	mock.ExpectQuery(`SELECT "status" FROM "articles" WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.ArticleDraft))
+	mock.ExpectExec(`DELETE FROM "articles"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "article_slugs"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if _, err := repo.DeleteArticle(context.Background(), &article_protos.DeleteArticleRequest{ArticleId: "r-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sitemapStorage implements SitemapStore on Postgres
+type sitemapStorage struct {
+	db *gorm.DB
+}
+
+// NewSitemapStore creates a new sitemapStorage
+func NewSitemapStore(db *gorm.DB) repos.SitemapStore {
+	return &sitemapStorage{db: db}
+}
+
+// PublishedSummary counts the published articles and finds the latest edit among them
+func (s *sitemapStorage) PublishedSummary(ctx context.Context) (int64, time.Time, error) {
+	var summary struct {
+		Count    int64
+		LastEdit *time.Time
+	}
+	err := s.db.WithContext(ctx).Model(&models.Article{}).
+		Select("COUNT(*) AS count, MAX(edited_at) AS last_edit").
+		Where("status = ? AND slug <> ''", models.ArticlePublished).
+		Scan(&summary).Error
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if summary.LastEdit == nil {
+		return summary.Count, time.Time{}, nil
+	}
+	return summary.Count, *summary.LastEdit, nil
+}
+
+// SitemapEntries pages through published articles by (created_at, id), new articles land on the
+// last page so earlier pages keep their contents
+func (s *sitemapStorage) SitemapEntries(ctx context.Context, afterCreated time.Time, afterID string, limit int) ([]models.SitemapEntry, error) {
+	query := s.db.WithContext(ctx).Model(&models.Article{}).
+		Select("id", "slug", "created_at", "edited_at").
+		Where("status = ? AND slug <> ''", models.ArticlePublished)
+	if afterID != "" {
+		query = query.Where("(created_at, id) > (?, ?)", afterCreated, afterID)
+	}
+	var entries []models.SitemapEntry
+	if err := query.Order("created_at, id").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SitemapPages lists the stored pages in order, without their bodies
+func (s *sitemapStorage) SitemapPages(ctx context.Context) ([]models.SitemapPage, error) {
+	var pages []models.SitemapPage
+	err := s.db.WithContext(ctx).
+		Select("page", "url_count", "fingerprint", "last_modified", "generated_at").
+		Order("page").
+		Find(&pages).Error
+	return pages, err
+}
+
+// SitemapPage returns a stored page with its body
+func (s *sitemapStorage) SitemapPage(ctx context.Context, page int) (*models.SitemapPage, error) {
+	var p models.SitemapPage
+	err := s.db.WithContext(ctx).Where("page = ?", page).Take(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "sitemap page not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SaveSitemapPage inserts or replaces a page
+func (s *sitemapStorage) SaveSitemapPage(ctx context.Context, page *models.SitemapPage) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "page"}},
+		UpdateAll: true,
+	}).Create(page).Error
+}
+
+// DeleteSitemapPages removes the pages numbered from and above
+func (s *sitemapStorage) DeleteSitemapPages(ctx context.Context, from int) (int64, error) {
+	result := s.db.WithContext(ctx).Where("page >= ?", from).Delete(&models.SitemapPage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/slug"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errSlugTaken = errors.New("slug taken concurrently, retrying")
+
+// ResolveSlug returns the id of the article a current or historical slug belongs to
+func (r *articleRepository) ResolveSlug(ctx context.Context, s string) (string, error) {
+	if s == "" {
+		return "", status.Error(codes.InvalidArgument, "slug is required")
+	}
+
+	var entry models.ArticleSlug
+	if err := r.db.WithContext(ctx).Where("slug = ?", s).Take(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", status.Error(codes.NotFound, "article not found")
+		}
+		return "", status.Errorf(codes.Internal, "failed to resolve slug: %v", err)
+	}
+	return entry.ArticleID, nil
+}
+
+// withSlugRetry runs fn in a transaction, retrying when another writer claimed the same slug first
+func (r *articleRepository) withSlugRetry(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	const maxRetries = 3 // TODO: get this value from config
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		err = r.db.WithContext(ctx).Transaction(fn)
+		if !errors.Is(err, errSlugTaken) {
+			return err
+		}
+	}
+	return err
+}
+
+// assignSlug reserves a slug for the title in the slug history and returns it, a historical slug
+// of the same article generated from the same base is reused so renaming back does not grow a
+// suffix. Matching on the recorded base keeps "top-10" from "Top 10" apart from a collision
+// suffix of "top"
+func assignSlug(tx *gorm.DB, articleID, title string) (string, error) {
+	base := slug.Make(title)
+	var entries []models.ArticleSlug
+	if err := tx.Where("slug = ? OR slug LIKE ?", base, base+"-%").Find(&entries).Error; err != nil {
+		return "", err
+	}
+	taken := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.ArticleID == articleID && e.Base == base {
+			return e.Slug, nil
+		}
+		taken = append(taken, e.Slug)
+	}
+
+	s := slug.WithSuffix(base, taken)
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ArticleSlug{Slug: s, ArticleID: articleID, Base: base})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", errSlugTaken
+	}
+	return s, nil
+}
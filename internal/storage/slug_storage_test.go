@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+// assignInTx runs assignSlug the way the repository does, inside a transaction
+func assignInTx(repo *articleRepository, articleID, title string) (s string, err error) {
+	err = repo.db.Transaction(func(tx *gorm.DB) error {
+		s, err = assignSlug(tx, articleID, title)
+		return err
+	})
+	return s, err
+}
+
+func expectSlugEntries(mock sqlmock.Sqlmock, base string, entries ...[3]string) {
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"slug", "article_id", "base"})
+	for _, e := range entries {
+		rows.AddRow(e[0], e[1], e[2])
+	}
+	mock.ExpectQuery(`SELECT \* FROM "article_slugs" WHERE slug = \$1 OR slug LIKE \$2`).
+		WithArgs(base, base+"-%").WillReturnRows(rows)
+}
+
+func TestAssignSlugNumberedTitleIsNotACollision(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	// "Top 10" renamed to "Top": top-10 only looks like a suffixed top
+	expectSlugEntries(mock, "top", [3]string{"top-10", "a-1", "top-10"})
+	mock.ExpectExec(`INSERT INTO "article_slugs" .* ON CONFLICT DO NOTHING`).
+		WithArgs("top", "a-1", "top", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s, err := assignInTx(repo, "a-1", "Top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "top" {
+		t.Errorf("got slug %q, want top", s)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAssignSlugReusesCollisionSuffix(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	expectSlugEntries(mock, "top", [3]string{"top", "a-2", "top"}, [3]string{"top-2", "a-1", "top"})
+	mock.ExpectCommit()
+
+	s, err := assignInTx(repo, "a-1", "TOP!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "top-2" {
+		t.Errorf("got slug %q, want the article's own top-2", s)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAssignSlugSuffixesOnCollision(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	expectSlugEntries(mock, "top", [3]string{"top", "a-2", "top"}, [3]string{"top-10", "a-1", "top-10"})
+	mock.ExpectExec(`INSERT INTO "article_slugs"`).
+		WithArgs("top-2", "a-1", "top", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s, err := assignInTx(repo, "a-1", "Top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "top-2" {
+		t.Errorf("got slug %q, want top-2", s)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/ruziba3vich/mm_article_service/internal/models"
+	"github.com/ruziba3vich/mm_article_service/internal/repos"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedMinIO decorates MinIOStorage with a span per operation
+type tracedMinIO struct {
+	next    repos.MinIOStorage
+	tracing *Tracing
+}
+
+// InstrumentMinIO wraps a MinIOStorage so every operation is traced
+func InstrumentMinIO(next repos.MinIOStorage, t *Tracing) repos.MinIOStorage {
+	return &tracedMinIO{next: next, tracing: t}
+}
+
+func (s *tracedMinIO) CreateFile(ctx context.Context, fileName string, fileContent []byte) (string, string, error) {
+	ctx, span := s.start(ctx, "minio.create_file", fileName)
+	defer span.End()
+	name, url, err := s.next.CreateFile(ctx, fileName, fileContent)
+	span.SetAttributes(attribute.Int("minio.object_size", len(fileContent)))
+	recordError(span, err)
+	return name, url, err
+}
+
+func (s *tracedMinIO) DeleteFile(ctx context.Context, fileName string) error {
+	ctx, span := s.start(ctx, "minio.delete_file", fileName)
+	defer span.End()
+	err := s.next.DeleteFile(ctx, fileName)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedMinIO) GetFileURL(ctx context.Context, fileName string) (string, error) {
+	ctx, span := s.start(ctx, "minio.get_file_url", fileName)
+	defer span.End()
+	url, err := s.next.GetFileURL(ctx, fileName)
+	recordError(span, err)
+	return url, err
+}
+
+func (s *tracedMinIO) PublicURL(fileName string) string {
+	return s.next.PublicURL(fileName)
+}
+
+func (s *tracedMinIO) WalkFiles(ctx context.Context, fn func(models.StoredFile) error) error {
+	ctx, span := s.tracing.tracer.Start(ctx, "minio.walk_files", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	var objects int
+	err := s.next.WalkFiles(ctx, func(f models.StoredFile) error {
+		objects++
+		return fn(f)
+	})
+	span.SetAttributes(attribute.Int("minio.objects", objects))
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedMinIO) OpenFile(ctx context.Context, fileName string) (io.ReadCloser, models.StoredFile, error) {
+	ctx, span := s.start(ctx, "minio.open_file", fileName)
+	defer span.End()
+	r, info, err := s.next.OpenFile(ctx, fileName)
+	span.SetAttributes(attribute.Int64("minio.object_size", info.Size))
+	recordError(span, err)
+	return r, info, err
+}
+
+func (s *tracedMinIO) PutFile(ctx context.Context, fileName string, r io.Reader, size int64) error {
+	ctx, span := s.start(ctx, "minio.put_file", fileName)
+	defer span.End()
+	err := s.next.PutFile(ctx, fileName, r, size)
+	span.SetAttributes(attribute.Int64("minio.object_size", size))
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedMinIO) start(ctx context.Context, name, fileName string) (context.Context, trace.Span) {
+	return s.tracing.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("minio.object", fileName)))
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
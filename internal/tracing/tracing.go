@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	instrumentationName = "github.com/ruziba3vich/mm_article_service"
+)
+
+type (
+	// Tracing owns the tracer provider, it is also installed as the global provider and
+	// W3C trace context propagator so outgoing calls carry the trace
+	Tracing struct {
+		provider trace.TracerProvider
+		tracer   trace.Tracer
+		shutdown func(context.Context) error
+	}
+)
+
+func NewTracing(cfg *config.Config) (*Tracing, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case ExporterNone, "":
+		provider := noop.NewTracerProvider()
+		return &Tracing{
+			provider: provider,
+			tracer:   provider.Tracer(instrumentationName),
+			shutdown: func(context.Context) error { return nil },
+		}, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Tracing.Endpoint)}
+		if cfg.Tracing.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Tracing.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.Tracing.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.Tracing.SamplePercent)/100))),
+	)
+	otel.SetTracerProvider(provider)
+	return &Tracing{
+		provider: provider,
+		tracer:   provider.Tracer(instrumentationName),
+		shutdown: provider.Shutdown,
+	}, nil
+}
+
+// Provider returns the tracer provider for instrumentation libraries such as otelgrpc
+func (t *Tracing) Provider() trace.TracerProvider {
+	return t.provider
+}
+
+// Shutdown flushes buffered spans
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	return t.shutdown(ctx)
+}
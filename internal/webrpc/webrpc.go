@@ -0,0 +1,76 @@
+package webrpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"connectrpc.com/vanguard/vanguardgrpc"
+	"github.com/ruziba3vich/mm_article_service/internal/cors"
+	"github.com/ruziba3vich/mm_article_service/internal/interceptors"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"google.golang.org/grpc"
+)
+
+type (
+	// Server lets browsers call ArticleService over gRPC-Web and Connect, requests are
+	// transcoded to gRPC and handled in-process by the same grpc.Server and interceptors
+	Server struct {
+		http *http.Server
+		port string
+	}
+)
+
+// NewServer builds the listener for every service registered on grpcServer, so it has
+// to be created after the services are registered
+func NewServer(cfg *config.Config, grpcServer *grpc.Server) (*Server, error) {
+	transcoder, err := vanguardgrpc.NewTranscoder(grpcServer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build gRPC-Web transcoder: %w", err)
+	}
+
+	// Connect and gRPC-Web work over HTTP/1.1, cleartext HTTP/2 is accepted for streaming clients
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &Server{
+		http: &http.Server{
+			Addr:      fmt.Sprintf(":%s", cfg.WebPort),
+			Handler:   cors.Handler(cfg.CORS, dropGatewayHeaders(transcoder)),
+			Protocols: protocols,
+		},
+		port: cfg.WebPort,
+	}, nil
+}
+
+// Start listens on the gRPC-Web port and serves in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", s.port, err)
+	}
+	log.Printf("gRPC-Web and Connect listening on port %s", s.port)
+	go func() {
+		if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("gRPC-Web server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown drains in-flight browser requests
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// dropGatewayHeaders removes metadata only the HTTP gateway may set, browsers reach the
+// interceptors directly here so their own address is already the peer
+func dropGatewayHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(interceptors.GatewayClientIPHeader)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,131 @@
+package webrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ruziba3vich/mm_article_service/genprotos/genprotos/article_protos"
+	"github.com/ruziba3vich/mm_article_service/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const getBySlugPath = "/article_protos.ArticleService/GetArticleBySlug"
+
+type stubArticleService struct {
+	article_protos.UnimplementedArticleServiceServer
+}
+
+func (stubArticleService) GetArticleBySlug(_ context.Context, req *article_protos.GetArticleBySlugRequest) (*article_protos.GetArticleBySlugResponse, error) {
+	return &article_protos.GetArticleBySlugResponse{
+		Article: &article_protos.ArticleEntity{Id: "a-1", Slug: req.GetSlug()},
+	}, nil
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	grpcServer := grpc.NewServer()
+	article_protos.RegisterArticleServiceServer(grpcServer, stubArticleService{})
+	srv, err := NewServer(&config.Config{
+		WebPort: "0",
+		CORS:    &config.CORSConfig{AllowedOrigins: []string{"https://app.example"}, MaxAge: 60},
+	}, grpcServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.http.Handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestConnectJSONOverHTTP1(t *testing.T) {
+	ts := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+getBySlugPath, bytes.NewBufferString(`{"slug":"hello-world"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connect-Protocol-Version", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status = %d, body %s", resp.StatusCode, body)
+	}
+	var got struct {
+		Article struct {
+			ID   string `json:"id"`
+			Slug string `json:"slug"`
+		} `json:"article"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Article.ID != "a-1" || got.Article.Slug != "hello-world" {
+		t.Fatalf("unexpected response %+v", got)
+	}
+}
+
+func TestGRPCWeb(t *testing.T) {
+	ts := newTestServer(t)
+
+	msg, _ := proto.Marshal(&article_protos.GetArticleBySlugRequest{Slug: "hello-world"})
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+getBySlugPath, bytes.NewReader(frame))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || len(body) < 5 || body[0] != 0 {
+		t.Fatalf("status = %d, body %q", resp.StatusCode, body)
+	}
+	size := binary.BigEndian.Uint32(body[1:5])
+	var got article_protos.GetArticleBySlugResponse
+	if err := proto.Unmarshal(body[5:5+size], &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.GetArticle().GetSlug() != "hello-world" {
+		t.Fatalf("unexpected response %v", &got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	ts := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodOptions, ts.URL+getBySlugPath, nil)
+	req.Header.Set("Origin", "https://app.example")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	// browsers send the requested headers lowercased and sorted
+	req.Header.Set("Access-Control-Request-Headers", "connect-protocol-version,content-type,idempotency-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("disallowed origin got Access-Control-Allow-Origin = %q", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,8 +16,22 @@ type (
 		MinIO       *MinIOConfig
 		Redis       *RedisConfig
 		PsqlCfg     *PsqlConfig
+		Reconcile   *ReconcileConfig
+		Idempotency *IdempotencyConfig
+		Tracing     *TracingConfig
+		RateLimit   *RateLimitConfig
+		CORS        *CORSConfig
+		Site        *SiteConfig
+		Feeds       *FeedsConfig
+		Sitemap     *SitemapConfig
 		GRPCPort    string
+		MaxMsgBytes int // Largest gRPC request accepted, bundles and article files travel inline
+		MetricsPort string
+		HTTPPort    string
+		APIBaseURL  string // Public address of the HTTP gateway, links it serves point there
+		WebPort     string
 		UserService string
+		AdminToken  string // Shared secret the maintenance RPCs require in x-admin-token, empty disables them
 	}
 
 	PsqlConfig struct {
@@ -29,6 +45,68 @@ type (
 		SecretKey string
 		Bucket    string
 		UrlExpiry int
+		PublicURL string // Address images are linked at in article bodies, the gateway's /files route unless a CDN serves the bucket
+	}
+
+	// ReconcileConfig holds likes reconciliation job settings
+	ReconcileConfig struct {
+		Interval  int // Seconds between runs, 0 disables the job
+		BatchSize int
+	}
+
+	// IdempotencyConfig holds idempotency-key replay settings
+	IdempotencyConfig struct {
+		TTL            int // Seconds a stored response is replayed for
+		RequestTimeout int // Seconds a request holding a key may run before it is cancelled
+		PendingTimeout int // Seconds after which an unfinished request no longer blocks its key, kept above RequestTimeout
+	}
+
+	// TracingConfig holds OpenTelemetry settings
+	TracingConfig struct {
+		Exporter      string // otlp, stdout or none
+		Endpoint      string // OTLP gRPC collector address
+		Insecure      bool
+		ServiceName   string
+		SamplePercent int
+	}
+
+	// RateLimitConfig holds token-bucket limits written as "<per minute>:<burst>"
+	RateLimitConfig struct {
+		Enabled bool
+		Default string
+		Methods string // Comma separated "<method>=<per minute>:<burst>" overrides
+
+		RedisTimeout     int // Milliseconds a Redis check may take before the in-memory limits answer
+		FallbackCooldown int // Seconds the in-memory limits are used after Redis failed
+
+		TrustedProxies []string // CIDRs of the proxies whose ClientIPHeader is believed
+		ClientIPHeader string   // Header those proxies put the client address in, X-Forwarded-For style
+	}
+
+	// CORSConfig holds the browser origins allowed to call the HTTP, gRPC-Web and Connect listeners
+	CORSConfig struct {
+		AllowedOrigins   []string
+		AllowCredentials bool
+		MaxAge           int // Seconds browsers may cache a preflight response
+	}
+
+	// SiteConfig describes the public website articles are read on, links handed to readers and
+	// search engines point there
+	SiteConfig struct {
+		Name    string
+		BaseURL string
+	}
+
+	// FeedsConfig holds RSS and Atom feed settings
+	FeedsConfig struct {
+		Items    int // Articles per feed
+		CacheTTL int // Seconds a rendered feed is served from Redis
+	}
+
+	// SitemapConfig holds sitemap generation job settings
+	SitemapConfig struct {
+		Interval int // Seconds between regenerations, 0 disables the job
+		PageSize int // URLs per sitemap file, at most 50000
 	}
 
 	// RedisConfig holds Redis settings
@@ -47,6 +125,8 @@ func LoadConfig() *Config {
 		log.Println("No .env file found. Using system environment variables.")
 	}
 
+	httpPort := getEnv("HTTP_PORT", "8080")
+	apiBaseURL := strings.TrimRight(getEnv("API_BASE_URL", "http://localhost:"+httpPort), "/")
 	return &Config{
 		MinIO: &MinIOConfig{
 			Endpoint:  getEnv("MINIO_ENDPOINT", "localhost:9000"),
@@ -54,6 +134,7 @@ func LoadConfig() *Config {
 			SecretKey: getEnv("MINIO_SECRET_KEY", "secretpass"),
 			Bucket:    getEnv("MINIO_BUCKET", "mediumlike"),
 			UrlExpiry: getEnvInt("MINIO_URL_EXPIRY", 3_600),
+			PublicURL: getEnv("MINIO_PUBLIC_URL", apiBaseURL+"/files"),
 		},
 		Redis: &RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -64,8 +145,58 @@ func LoadConfig() *Config {
 		PsqlCfg: &PsqlConfig{
 			Dsn: getEnv("DB_DSN", "host=postgres user=postgres password=secret dbname=article_service port=5432 sslmode=disable TimeZone=Asia/Tashkent"),
 		},
+		Reconcile: &ReconcileConfig{
+			Interval:  getEnvInt("RECONCILE_LIKES_INTERVAL", 3_600),
+			BatchSize: getEnvInt("RECONCILE_LIKES_BATCH_SIZE", 500),
+		},
+		Idempotency: &IdempotencyConfig{
+			TTL:            getEnvInt("IDEMPOTENCY_TTL", 86_400),
+			RequestTimeout: getEnvInt("IDEMPOTENCY_REQUEST_TIMEOUT", 60),
+			PendingTimeout: getEnvInt("IDEMPOTENCY_PENDING_TIMEOUT", 120),
+		},
+		Tracing: &TracingConfig{
+			Exporter:      getEnv("TRACING_EXPORTER", "none"),
+			Endpoint:      getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			Insecure:      getEnvBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+			ServiceName:   getEnv("OTEL_SERVICE_NAME", "article_service"),
+			SamplePercent: getEnvInt("TRACING_SAMPLE_PERCENT", 100),
+		},
+		RateLimit: &RateLimitConfig{
+			Enabled: getEnvBool("RATE_LIMIT_ENABLED", true),
+			Default: getEnv("RATE_LIMIT_DEFAULT", "600:100"),
+			Methods: getEnv("RATE_LIMIT_METHODS", "CreateArticle=30:10,RewriteArticle=30:10,UpdateArticle=60:20,LikeArticle=120:30,UnlikeArticle=120:30,AddReaction=120:30,RemoveReaction=120:30,ImportMarkdownBundle=10:3"),
+
+			RedisTimeout:     getEnvInt("RATE_LIMIT_REDIS_TIMEOUT_MS", 50),
+			FallbackCooldown: getEnvInt("RATE_LIMIT_FALLBACK_COOLDOWN", 30),
+
+			TrustedProxies: getEnvList("RATE_LIMIT_TRUSTED_PROXIES", []string{"127.0.0.0/8", "::1/128"}),
+			ClientIPHeader: getEnv("RATE_LIMIT_CLIENT_IP_HEADER", "x-forwarded-for"),
+		},
+		CORS: &CORSConfig{
+			AllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS", []string{"*"}),
+			AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           getEnvInt("CORS_MAX_AGE", 7_200),
+		},
+		Site: &SiteConfig{
+			Name:    getEnv("SITE_NAME", "Articles"),
+			BaseURL: strings.TrimRight(getEnv("SITE_BASE_URL", "http://localhost:3000"), "/"),
+		},
+		Feeds: &FeedsConfig{
+			Items:    getEnvInt("FEED_ITEMS", 20),
+			CacheTTL: getEnvInt("FEED_CACHE_TTL", 300),
+		},
+		Sitemap: &SitemapConfig{
+			Interval: getEnvInt("SITEMAP_INTERVAL", 900),
+			PageSize: getEnvInt("SITEMAP_PAGE_SIZE", 50_000),
+		},
 		GRPCPort:    getEnv("GRPC_PORT", "7878"),
+		MaxMsgBytes: getEnvInt("GRPC_MAX_MESSAGE_BYTES", 32<<20),
+		MetricsPort: getEnv("METRICS_PORT", "9100"),
+		HTTPPort:    httpPort,
+		APIBaseURL:  apiBaseURL,
+		WebPort:     getEnv("GRPC_WEB_PORT", "8081"),
 		UserService: getEnv("USER_SERVICE", "mm_user_service-app:7373"),
+		AdminToken:  getEnv("ADMIN_TOKEN", ""),
 	}
 }
 
@@ -88,3 +219,28 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+// getEnvBool retrieves a boolean environment variable
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+// getEnvList retrieves a comma separated environment variable, blank entries are dropped
+func getEnvList(key string, fallback []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}